@@ -3,7 +3,7 @@ from datetime import datetime
 
 import polars as pl
 
-from src.utilities import version_extractor, name_extractor
+from src.utilities import version_extractor, name_extractor, write_ndjson
 
 
 def convert_to_rfc3339(date: str):
@@ -73,6 +73,8 @@ def process(no_extra: bool = True):
 
     with open('../../data/output/pypi-bq-dependencies420k-latest.json', 'w') as file:
         json.dump(final_result, file)
+    # Uncomment next line to also write one package per line, which can be appended to
+    # write_ndjson(results.values(), '../../data/output/pypi-bq-dependencies420k-latest.ndjson')
 
 
 if __name__ == '__main__':
@@ -65,6 +65,8 @@ def process():
     # WARNING: This generated the file in the old format.
     # It needs to be changed by adding "{"pkgs":" in the beginning of the file and a "}" at the end
     normalized_json_df.to_json('../../data/output/pypi-repology-dependencies.json', orient='records')
+    # Uncomment next line to write one package per line instead, which the graph reads without any change
+    # normalized_json_df.to_json('../../data/output/pypi-repology-dependencies.ndjson', orient='records', lines=True)
 
 
 if __name__ == '__main__':
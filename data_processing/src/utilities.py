@@ -1,4 +1,5 @@
-from typing import Optional
+import json
+from typing import Iterable, Optional
 
 
 def version_extractor(string: str) -> str:
@@ -22,3 +23,11 @@ def name_extractor(string: str) -> Optional[str]:
     no_semicolon = no_parenthesis.split(';')[0].strip()
 
     return no_semicolon
+
+
+def write_ndjson(packages: Iterable[dict], path: str, mode: str = 'w'):
+    # Writes one package per line. The graph reads these files directly, and more packages can be appended with mode='a'
+    with open(path, mode) as file:
+        for package in packages:
+            file.write(json.dumps(package))
+            file.write('\n')
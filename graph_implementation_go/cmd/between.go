@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// betweenCmd represents the between command
+var betweenCmd = &cobra.Command{
+	Use:   "between",
+	Short: "Finds all packages released between two dates",
+	Long:  `Finds all packages released between the dates given with --from and --to.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		beginTime, endTime, _, err := intervalFromFlags()
+		if err != nil {
+			return err
+		}
+		pg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		nodes, err := findAllPackagesBetween(pg, beginTime, endTime)
+		if err != nil {
+			return err
+		}
+		printNodes(nodes)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(betweenCmd)
+
+	addGraphFlags(betweenCmd)
+	addIntervalFlags(betweenCmd)
+	_ = betweenCmd.MarkFlagRequired("from")
+	_ = betweenCmd.MarkFlagRequired("to")
+}
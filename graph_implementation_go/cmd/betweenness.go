@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// betweennessCmd represents the betweenness command
+var betweennessCmd = &cobra.Command{
+	Use:   "betweenness",
+	Short: "Finds the n nodes with the highest betweenness",
+	Long:  `Finds the n nodes with the highest betweenness.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateTop(); err != nil {
+			return err
+		}
+		pg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		return printHighestBetweenness(cmd.Context(), pg, top)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(betweennessCmd)
+
+	addGraphFlags(betweennessCmd)
+	addTopFlag(betweennessCmd)
+}
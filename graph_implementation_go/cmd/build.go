@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/spf13/cobra"
+)
+
+var (
+	outPath    string
+	reportPath string
+)
+
+// buildCmd represents the build command
+var buildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Creates the graph and writes it to a snapshot",
+	Long: `Creates the graph from JSON files and writes it to a binary snapshot. The snapshot can then be given to
+the other commands with --snapshot, which is a lot faster than creating the graph again from the JSON files.
+
+Several ecosystems can be put in the same graph by prefixing every input with its ecosystem:
+  build --input npm=npm.json --input pypi=pypi.ndjson --out graph.stm
+
+With --report, the build report is written to a JSON file. It counts the versions, constraints and timestamps that
+could not be parsed and the dependencies that did not resolve to any version, with the packages that have the most
+issues and samples of the raw strings.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pg, report, err := createGraph(cmd)
+		if err != nil {
+			return err
+		}
+		if reportPath != "" {
+			fmt.Printf("Writing the build report to %s\n", reportPath)
+			if err := writeReport(reportPath, report); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("Writing the snapshot to %s\n", outPath)
+		t1 := time.Now().Unix()
+		if err := g.WriteSnapshot(outPath, pg); err != nil {
+			return err
+		}
+		t2 := time.Now().Unix()
+		fmt.Printf("Writing the snapshot took %d seconds\n", t2-t1)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(buildCmd)
+
+	addInputFlags(buildCmd)
+	buildCmd.Flags().StringVarP(&outPath, "out", "o", "graph.stm", "path of the snapshot file")
+	buildCmd.Flags().StringVar(&reportPath, "report", "", "path of the JSON file the build report is written to")
+	_ = buildCmd.MarkFlagRequired("input")
+}
+
+// writeReport writes the build report to the file at path as indented JSON.
+func writeReport(path string, report *g.BuildReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// cyclesCmd represents the cycles command
+var cyclesCmd = &cobra.Command{
+	Use:   "cycles",
+	Short: "Finds the dependency cycles of the graph",
+	Long: `Finds the dependency cycles of the graph, which are the strongly connected components made of more than one
+package version. The cycles between versions of the same packages are grouped together, with the groups of the most
+packages first. When --json is given, the report is printed as JSON. When --from and --to are given, only the
+packages released between the two dates are taken into account. When --kind is given, only the dependencies of the
+given kinds are followed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filters, err := filtersFromFlags()
+		if err != nil {
+			return err
+		}
+		pg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		view, err := filters.apply(pg)
+		if err != nil {
+			return err
+		}
+		report := view.Cycles()
+		if jsonOutput {
+			return printJSON(report)
+		}
+		fmt.Println(report)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cyclesCmd)
+
+	addGraphFlags(cyclesCmd)
+	addIntervalFlags(cyclesCmd)
+	addKindFlag(cyclesCmd)
+	addJSONFlag(cyclesCmd)
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// dependentsCmd represents the dependents command
+var dependentsCmd = &cobra.Command{
+	Use:   "dependents",
+	Short: "Finds all the packages that depend on a package",
+	Long: `Finds all the packages that depend on a package, directly or transitively. This shows what could break
+when the package is broken. When --from and --to are given, only the packages released between the two dates
+are taken into account. When --kind is given, only the dependencies of the given kinds are followed. When --depth,
+--allow or --deny is given, only the packages that are at most --depth dependencies away, and whose names are
+selected by the patterns, are shown with their depth. The packages that depend directly on the package have a
+depth of 1.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filters, err := filtersFromFlags()
+		if err != nil {
+			return err
+		}
+		traversal, limited, err := traversalFromFlags()
+		if err != nil {
+			return err
+		}
+		pg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		key, err := keyFromFlags(pg)
+		if err != nil {
+			return err
+		}
+		view, err := filters.apply(pg)
+		if err != nil {
+			return err
+		}
+		if limited {
+			dependents, err := view.TraverseDependents(cmd.Context(), key, traversal)
+			if err != nil {
+				return err
+			}
+			printReachedNodes(dependents)
+			return nil
+		}
+		dependents, err := view.TransitiveDependents(key)
+		if err != nil {
+			return err
+		}
+		printNodes(&dependents)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dependentsCmd)
+
+	addGraphFlags(dependentsCmd)
+	addPackageFlags(dependentsCmd)
+	addIntervalFlags(dependentsCmd)
+	addKindFlag(dependentsCmd)
+	addTraversalFlags(dependentsCmd)
+}
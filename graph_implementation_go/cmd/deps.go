@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var direct bool
+
+// depsCmd represents the deps command
+var depsCmd = &cobra.Command{
+	Use:   "deps",
+	Short: "Finds all the possible dependencies of a package",
+	Long: `Finds all the possible dependencies of a package. When --from and --to are given, only the packages
+released between the two dates are taken into account. When --kind is given, only the dependencies of the given
+kinds are followed. When --direct is given, only the direct dependencies are shown, together with the constraints
+that explain why they were selected. When --depth, --allow or --deny is given, only the dependencies that are at
+most --depth dependencies away, and whose names are selected by the patterns, are shown with their depth. The
+direct dependencies have a depth of 1.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filters, err := filtersFromFlags()
+		if err != nil {
+			return err
+		}
+		traversal, limited, err := traversalFromFlags()
+		if err != nil {
+			return err
+		}
+		if direct && limited {
+			return errors.New("--direct cannot be used with --depth, --allow or --deny")
+		}
+		pg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		key, err := keyFromFlags(pg)
+		if err != nil {
+			return err
+		}
+		view, err := filters.apply(pg)
+		if err != nil {
+			return err
+		}
+		if direct {
+			dependencies, err := view.Dependencies(key)
+			if err != nil {
+				return err
+			}
+			for _, dependency := range dependencies {
+				fmt.Println(dependency)
+			}
+			return nil
+		}
+		if limited {
+			dependencies, err := view.TraverseDependencies(cmd.Context(), key, traversal)
+			if err != nil {
+				return err
+			}
+			printReachedNodes(dependencies)
+			return nil
+		}
+		dependencies, err := view.TransitiveDependencies(key)
+		if err != nil {
+			return err
+		}
+		printNodes(&dependencies)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(depsCmd)
+
+	addGraphFlags(depsCmd)
+	addPackageFlags(depsCmd)
+	addIntervalFlags(depsCmd)
+	addKindFlag(depsCmd)
+	addTraversalFlags(depsCmd)
+	depsCmd.Flags().BoolVar(&direct, "direct", false, "only show the direct dependencies and their constraints")
+}
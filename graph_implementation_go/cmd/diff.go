@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <from> <to>",
+	Short: "Compares the dependencies of two package versions",
+	Long: `Compares the dependencies of two package versions (from and to, given as name@version), such as two releases
+of the same package. The dependencies of both are resolved to the latest version of every package, like the resolve
+command does, and the packages that were added, removed or resolved to another version are printed. When --json is
+given, the differences are printed as JSON. When --from and --to are given, only the packages released between the
+two dates are taken into account. When --kind is given, only the dependencies of the given kinds are followed.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filters, err := filtersFromFlags()
+		if err != nil {
+			return err
+		}
+		for _, arg := range args { // The arguments are checked before the graph is loaded, which can take long
+			if err := packageFromArg(arg); err != nil {
+				return err
+			}
+		}
+		pg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		fromKey, err := keyFromArg(pg, args[0])
+		if err != nil {
+			return err
+		}
+		toKey, err := keyFromArg(pg, args[1])
+		if err != nil {
+			return err
+		}
+		view, err := filters.apply(pg)
+		if err != nil {
+			return err
+		}
+		diff, err := view.DiffClosures(fromKey, toKey)
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			return printJSON(diff)
+		}
+		fmt.Println(diff)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	addGraphFlags(diffCmd)
+	addEcosystemFlag(diffCmd)
+	addIntervalFlags(diffCmd)
+	addKindFlag(diffCmd)
+	addJSONFlag(diffCmd)
+}
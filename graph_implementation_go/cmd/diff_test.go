@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+// captureStdout runs f and returns what it wrote on stdout. The commands print with the fmt functions, which write to
+// os.Stdout directly.
+func captureStdout(t *testing.T, f func()) []byte {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		output <- data
+	}()
+	f()
+	w.Close()
+	return <-output
+}
+
+func TestDiffJSONOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.ndjson")
+	content := `{"name": "A", "versions": {"1.0.0": {"timestamp": "2021-04-01T20:15:37Z", "dependencies": {"B": "^1.0.0"}}, "2.0.0": {"timestamp": "2021-06-01T20:15:37Z", "dependencies": {"C": "^1.0.0"}}}}
+{"name": "B", "versions": {"1.0.0": {"timestamp": "2021-03-01T20:15:37Z", "dependencies": {}}}}
+{"name": "C", "versions": {"1.0.0": {"timestamp": "2021-05-01T20:15:37Z", "dependencies": {}}}}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var err error
+	output := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"diff", "A@1.0.0", "A@2.0.0", "--input", path, "--from", "01-01-2021", "--to",
+			"31-12-2021", "--json"})
+		err = rootCmd.ExecuteContext(context.Background())
+	})
+	if err != nil {
+		t.Fatalf("Running diff failed: %v", err)
+	}
+
+	// Decoding the whole output, rather than the first value, makes sure nothing else was printed on stdout
+	var diff g.ClosureDiff
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	if err := decoder.Decode(&diff); err != nil {
+		t.Fatalf("Expected JSON on stdout, got %q: %v", output, err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		t.Errorf("Expected only JSON on stdout, got %q", output)
+	}
+	if len(diff.Added) != 1 || diff.Added[0].Name != "C" || len(diff.Removed) != 1 || diff.Removed[0].Name != "B" {
+		t.Errorf("Expected C to be added and B to be removed, got %+v", diff)
+	}
+}
@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/spf13/cobra"
+)
+
+// dateLayout is the layout of the dates accepted by the CLI, both in the prompts and in the flags (DD-MM-YYYY).
+const dateLayout = "02-01-2006"
+
+// The values of the flags shared by the non-interactive commands. Only one command runs per invocation, so the
+// commands can safely bind their flags to the same variables.
+var (
+	inputPaths     []string
+	snapshotPath   string
+	isUsingMaven   bool
+	dialectName    string
+	ecosystemName  string
+	formatName     string
+	strict         bool
+	packageName    string
+	packageVersion string
+	fromDate       string
+	toDate         string
+	kindNames      []string
+	top            int
+	maxDepth       int
+	allowPatterns  []string
+	denyPatterns   []string
+	jsonOutput     bool
+)
+
+// addGraphFlags adds the flags needed to create the graph to cmd. The graph is either created from JSON files or
+// loaded from a snapshot written by the build command.
+func addGraphFlags(cmd *cobra.Command) {
+	addInputFlags(cmd)
+	cmd.Flags().StringVarP(&snapshotPath, "snapshot", "s", "", "path to a graph snapshot written by the build command")
+}
+
+// addInputFlags adds the flags selecting the input files and how they are read to cmd.
+func addInputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVarP(&inputPaths, "input", "i", nil, "path to a JSON file used to create the graph, optionally "+
+		"prefixed with its ecosystem (npm=file.json). Can be repeated to create a graph of several ecosystems")
+	addFormatFlag(cmd)
+	addDialectFlags(cmd)
+	cmd.Flags().BoolVar(&strict, "strict", false, "fail on the first package of the input that cannot be decoded or has an invalid timestamp, instead of leaving it out")
+}
+
+// addFormatFlag adds the flag selecting the format of the input file to cmd.
+func addFormatFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&formatName, "format", g.AutoFormat.String(), "format of the input file (auto, json or ndjson)")
+}
+
+// addDialectFlags adds the flags selecting how the versions and the constraints of the input are interpreted to cmd.
+func addDialectFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&dialectName, "dialect", "d", g.SemverDialect.Name(), "version dialect of the packages data (semver, maven, pypi or npm)")
+	cmd.Flags().BoolVarP(&isUsingMaven, "maven", "m", false, "whether the packages data is coming from Maven")
+	_ = cmd.Flags().MarkDeprecated("maven", "use --dialect maven instead")
+}
+
+// dialectFromFlags returns the dialect selected with the --dialect flag, or with the older --maven flag, and the name
+// of the ecosystem of the inputs that are not prefixed with one.
+func dialectFromFlags(cmd *cobra.Command) (g.Dialect, string, error) {
+	if isUsingMaven {
+		if cmd.Flags().Changed("dialect") && dialectName != g.MavenDialect.Name() {
+			return nil, "", fmt.Errorf("--maven cannot be used with --dialect %s", dialectName)
+		}
+		return g.MavenDialect, g.MavenDialect.Name(), nil
+	}
+	dialect, err := g.DialectByName(dialectName)
+	return dialect, strings.ToLower(dialectName), err
+}
+
+// inputsFromFlags returns the inputs given with the --input flags. An input prefixed with the name of a dialect, as in
+// pypi=file.json, is an ecosystem of its own read with that dialect. The other inputs use the --dialect flag.
+func inputsFromFlags(cmd *cobra.Command) ([]g.Input, error) {
+	dialect, ecosystem, err := dialectFromFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+	format, err := g.ParseInputFormat(formatName)
+	if err != nil {
+		return nil, err
+	}
+	inputs := make([]g.Input, len(inputPaths))
+	for i, path := range inputPaths {
+		inputs[i] = g.Input{Path: path, Format: format, Ecosystem: ecosystem, Dialect: dialect}
+		// Paths that merely contain '=' are kept as they are
+		if prefix, rest, ok := strings.Cut(path, "="); ok {
+			if prefixDialect, err := g.DialectByName(prefix); err == nil {
+				inputs[i] = g.Input{Path: rest, Format: format, Ecosystem: strings.ToLower(prefix), Dialect: prefixDialect}
+			}
+		}
+	}
+	return inputs, nil
+}
+
+// addPackageFlags adds the flags needed to select a single package version to cmd.
+func addPackageFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&packageName, "package", "p", "", "name of the package")
+	cmd.Flags().StringVarP(&packageVersion, "version", "v", "", "version of the package")
+	addEcosystemFlag(cmd)
+	_ = cmd.MarkFlagRequired("package")
+	_ = cmd.MarkFlagRequired("version")
+}
+
+// addEcosystemFlag adds the flag selecting the ecosystem of the package to cmd.
+func addEcosystemFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&ecosystemName, "ecosystem", "e", "", "ecosystem of the package, only needed when the graph has several")
+}
+
+// packageFromArg sets the package and the version selected by an argument of the form name@version, as the --package
+// and --version flags would. The version follows the last @, so scoped npm packages such as @types/node@18.0.0 work.
+func packageFromArg(arg string) error {
+	at := strings.LastIndex(arg, "@")
+	if at <= 0 || at == len(arg)-1 {
+		return fmt.Errorf("%q must be of the form name@version", arg)
+	}
+	packageName, packageVersion = arg[:at], arg[at+1:]
+	return nil
+}
+
+// keyFromArg returns the key of the package selected by an argument of the form name@version and the --ecosystem
+// flag. See packageFromArg and keyFromFlags.
+func keyFromArg(pg *g.PackageGraph, arg string) (g.NodeKey, error) {
+	if err := packageFromArg(arg); err != nil {
+		return g.NodeKey{}, err
+	}
+	return keyFromFlags(pg)
+}
+
+// addIntervalFlags adds the flags needed to select a time interval to cmd.
+func addIntervalFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&fromDate, "from", "", "beginning date of the interval (DD-MM-YYYY)")
+	cmd.Flags().StringVar(&toDate, "to", "", "end date of the interval (DD-MM-YYYY)")
+}
+
+// addKindFlag adds the flag selecting the kinds of dependencies that are followed to cmd.
+func addKindFlag(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVarP(&kindNames, "kind", "k", nil, "kinds of dependencies to follow (runtime, dev, optional, peer, test), all of them by default")
+}
+
+// addTraversalFlags adds the flags limiting how far a traversal goes and which packages it visits to cmd.
+func addTraversalFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&maxDepth, "depth", 0, "number of dependencies followed at most from the package, any number when 0")
+	cmd.Flags().StringSliceVar(&allowPatterns, "allow", nil, "only visit the packages whose name matches one of these glob patterns (lodash.*)")
+	cmd.Flags().StringSliceVar(&denyPatterns, "deny", nil, "never visit the packages whose name matches one of these glob patterns")
+}
+
+// traversalFromFlags returns the options selected with the --depth, --allow and --deny flags. The returned bool is
+// false when none of them was given, which means that the whole closure should be traversed.
+func traversalFromFlags() (g.TraversalOptions, bool, error) {
+	if maxDepth < 0 {
+		return g.TraversalOptions{}, false, errors.New("--depth cannot be negative")
+	}
+	options := g.TraversalOptions{MaxDepth: maxDepth, Allow: allowPatterns, Deny: denyPatterns}
+	if err := options.Validate(); err != nil {
+		return options, false, err
+	}
+	return options, maxDepth > 0 || len(allowPatterns) > 0 || len(denyPatterns) > 0, nil
+}
+
+// addJSONFlag adds the flag printing the result of cmd as JSON instead of text.
+func addJSONFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "print the result as JSON")
+}
+
+// addTopFlag adds the flag selecting how many results are shown to cmd.
+func addTopFlag(cmd *cobra.Command) {
+	cmd.Flags().IntVarP(&top, "top", "n", 10, "number (n > 0) of highest-ranked packages to show")
+}
+
+// loadGraph creates the graph from the files given with the --input flags, or loads it from the snapshot given with
+// the --snapshot flag. What it is doing is printed on stderr, so the output of the commands can be piped.
+func loadGraph(cmd *cobra.Command) (*g.PackageGraph, error) {
+	switch {
+	case len(inputPaths) > 0 && snapshotPath != "":
+		return nil, errors.New("only one of --input and --snapshot can be used")
+	case snapshotPath != "":
+		fmt.Fprintln(os.Stderr, "Loading the graph snapshot")
+		return g.LoadSnapshot(snapshotPath)
+	case len(inputPaths) > 0:
+		pg, _, err := createGraph(cmd)
+		return pg, err
+	default:
+		return nil, errors.New("either --input or --snapshot must be given")
+	}
+}
+
+// createGraph creates the graph from the files given with the --input flags and prints the summary of its build
+// report on stderr.
+func createGraph(cmd *cobra.Command) (*g.PackageGraph, *g.BuildReport, error) {
+	inputs, err := inputsFromFlags(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	progress := newProgressBar(os.Stderr)
+	options := g.BuildOptions{Mode: g.BestEffort, Progress: progress}
+	if strict {
+		options.Mode = g.Strict
+	}
+	fmt.Fprintln(os.Stderr, "Creating the graph. This may take a while!")
+	pg, report, err := g.CreateGraph(cmd.Context(), options, inputs...)
+	progress.finish()
+	if err != nil {
+		return nil, nil, err
+	}
+	fmt.Fprintln(os.Stderr, report)
+	return pg, report, nil
+}
+
+// keyFromFlags returns the key of the package selected with the --ecosystem, --package and --version flags. The
+// ecosystem can be left out when all the packages of the graph belong to the same one.
+func keyFromFlags(pg *g.PackageGraph) (g.NodeKey, error) {
+	key := g.NodeKey{Ecosystem: ecosystemName, Name: packageName, Version: packageVersion}
+	if ecosystemName != "" {
+		return key, nil
+	}
+	ecosystems := pg.Ecosystems()
+	if len(ecosystems) > 1 {
+		return key, fmt.Errorf("--ecosystem must be given, the graph has packages of %s", strings.Join(ecosystems, ", "))
+	}
+	if len(ecosystems) == 1 {
+		key.Ecosystem = ecosystems[0]
+	}
+	return key, nil
+}
+
+// intervalFromFlags parses the --from and --to flags. The returned bool is false when neither flag was given, which
+// means that no time filtering should be done.
+func intervalFromFlags() (time.Time, time.Time, bool, error) {
+	if fromDate == "" && toDate == "" {
+		return time.Time{}, time.Time{}, false, nil
+	}
+	if fromDate == "" || toDate == "" {
+		return time.Time{}, time.Time{}, false, errors.New("--from and --to must be used together")
+	}
+	beginTime, err := time.Parse(dateLayout, fromDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, fmt.Errorf("--from must be in the format DD-MM-YYYY: %w", err)
+	}
+	endTime, err := time.Parse(dateLayout, toDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, fmt.Errorf("--to must be in the format DD-MM-YYYY: %w", err)
+	}
+	return beginTime, endTime, true, nil
+}
+
+// viewFilters holds the filters selected with the --from, --to and --kind flags.
+type viewFilters struct {
+	interval           bool
+	beginTime, endTime time.Time
+	kinds              []g.DependencyKind
+}
+
+// filtersFromFlags parses the --from, --to and --kind flags.
+func filtersFromFlags() (viewFilters, error) {
+	var filters viewFilters
+	var err error
+	filters.beginTime, filters.endTime, filters.interval, err = intervalFromFlags()
+	if err != nil {
+		return filters, err
+	}
+	for _, name := range kindNames {
+		kind, err := g.ParseDependencyKind(name)
+		if err != nil {
+			return filters, fmt.Errorf("--kind: %w", err)
+		}
+		filters.kinds = append(filters.kinds, kind)
+	}
+	return filters, nil
+}
+
+// apply returns the part of pg that the filters selected. The graph itself is returned when no filter was selected.
+func (f viewFilters) apply(pg *g.PackageGraph) (*g.PackageGraph, error) {
+	view := pg
+	if f.interval {
+		var err error
+		if view, err = filterBetween(view, f.beginTime, f.endTime); err != nil {
+			return nil, err
+		}
+	}
+	if len(f.kinds) > 0 {
+		return view.Filter(g.OfKinds(f.kinds...))
+	}
+	return view, nil
+}
+
+// validateTop checks that the --top flag holds a positive number.
+func validateTop() error {
+	if top <= 0 {
+		return errors.New("--top must be a number larger than 0")
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+// The functions in this file implement the queries shared by the interactive start command and the non-interactive
+// commands. They take all of their input as arguments, so they never prompt the user.
+
+func printNodes(nodes *[]g.NodeInfo) {
+	for _, node := range *nodes {
+		fmt.Println(node)
+	}
+}
+
+func printReachedNodes(nodes []g.ReachedNode) {
+	for _, node := range nodes {
+		fmt.Println(node)
+	}
+}
+
+// printJSON prints the value as indented JSON.
+func printJSON(value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// findAllPackagesBetween returns the packages released between beginTime and endTime. External nodes are never
+// released, so they are left out.
+func findAllPackagesBetween(pg *g.PackageGraph, beginTime, endTime time.Time) (*[]g.NodeInfo, error) {
+	view, err := pg.Filter(g.ReleasedBetween(beginTime, endTime))
+	if err != nil {
+		return nil, err
+	}
+	nodesInInterval := view.Nodes()
+	return &nodesInInterval, nil
+}
+
+// filterBetween returns the part of pg with only the packages released between beginTime and endTime. The graph
+// itself is left untouched, so it can still be used for other queries afterwards.
+func filterBetween(pg *g.PackageGraph, beginTime, endTime time.Time) (*g.PackageGraph, error) {
+	t1 := time.Now().Unix()
+	view, err := pg.Filter(g.ReleasedBetween(beginTime, endTime))
+	if err != nil {
+		return nil, err
+	}
+	t2 := time.Now().Unix()
+	fmt.Fprintf(os.Stderr, "Graph filtering took %d seconds\n", t2-t1)
+	return view, nil
+}
+
+func printMostUsedPackages(ctx context.Context, pg *g.PackageGraph, count int) error {
+	fmt.Fprintln(os.Stderr, "Running PageRank")
+	progress := newProgressBar(os.Stderr)
+	pr, err := g.PageRank(ctx, pg.Directed(), progress)
+	progress.finish()
+	if err != nil {
+		return err
+	}
+	keys := make([]int64, 0, len(pr))
+	aggregated := make(map[string]float64)
+
+	for k, value := range pr {
+		keys = append(keys, k)
+		info, _ := pg.Node(k)
+		aggregated[info.Name] += value
+	}
+
+	aggregatedKeys := make([]string, 0, len(aggregated))
+
+	for k := range aggregated {
+		aggregatedKeys = append(aggregatedKeys, k)
+	}
+
+	sort.SliceStable(aggregatedKeys, func(i, j int) bool {
+		return aggregated[aggregatedKeys[i]] > aggregated[aggregatedKeys[j]]
+	})
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		return pr[keys[i]] > pr[keys[j]]
+	})
+
+	for i := 0; i < count && i < len(keys); i++ {
+		info, _ := pg.Node(keys[i])
+		fmt.Printf("The %d-th highest-ranked node (%v) has rank %f \n", i, info, pr[keys[i]])
+	}
+
+	fmt.Print("\n---------------------------------------------\n\n")
+
+	for i := 0; i < count && i < len(aggregatedKeys); i++ {
+		fmt.Printf("The %d-th highest-ranked package (%v) has rank %f \n", i, aggregatedKeys[i], aggregated[aggregatedKeys[i]])
+	}
+	return nil
+}
+
+func printHighestBetweenness(ctx context.Context, pg *g.PackageGraph, count int) error {
+	fmt.Fprintln(os.Stderr, "Running betweenness algorithm")
+	progress := newProgressBar(os.Stderr)
+	betweenness, err := g.Betweenness(ctx, pg.Directed(), progress)
+	progress.finish()
+	if err != nil {
+		return err
+	}
+	keys := make([]int64, 0, len(betweenness))
+	for k := range betweenness {
+		keys = append(keys, k)
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		return betweenness[keys[i]] > betweenness[keys[j]]
+	})
+
+	for i := 0; i < count && i < len(keys); i++ {
+		info, _ := pg.Node(keys[i])
+		fmt.Printf("The %d-th highest-ranked node (%v) has a betweenness score of %f \n", i, info, betweenness[keys[i]])
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// progressBarWidth is the number of characters of the bar itself
+	progressBarWidth = 30
+	// progressBarInterval is the minimum time between two redraws of the bar
+	progressBarInterval = 100 * time.Millisecond
+)
+
+// progressBar renders the progress of the long operations of the graph package on a single line, which is redrawn at
+// most every progressBarInterval. Every stage gets a line of its own.
+type progressBar struct {
+	w        io.Writer
+	stage    string
+	lastDraw time.Time
+}
+
+func newProgressBar(w io.Writer) *progressBar {
+	return &progressBar{w: w}
+}
+
+// Progress implements graph.ProgressReporter.
+func (p *progressBar) Progress(stage string, done, total int) {
+	if stage != p.stage {
+		p.finish()
+		p.stage = stage
+	} else if done != total && time.Since(p.lastDraw) < progressBarInterval {
+		return
+	}
+	p.lastDraw = time.Now()
+
+	if total <= 0 {
+		fmt.Fprintf(p.w, "\r%s: %d", stage, done)
+		return
+	}
+	filled := done * progressBarWidth / total
+	fmt.Fprintf(p.w, "\r%s [%s%s] %6.2f%% (%d / %d)", stage, strings.Repeat("=", filled),
+		strings.Repeat(" ", progressBarWidth-filled), float64(done)/float64(total)*100, done, total)
+}
+
+// finish ends the line of the current stage, if any.
+func (p *progressBar) finish() {
+	if p.stage != "" {
+		fmt.Fprintln(p.w)
+		p.stage = ""
+	}
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// rankCmd represents the rank command
+var rankCmd = &cobra.Command{
+	Use:   "rank",
+	Short: "Finds the n most used packages using PageRank",
+	Long: `Finds the n most used packages using PageRank, both per package version and aggregated per package.
+When --from and --to are given, only the packages released between the two dates are taken into account.
+When --kind is given, only the dependencies of the given kinds are followed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateTop(); err != nil {
+			return err
+		}
+		filters, err := filtersFromFlags()
+		if err != nil {
+			return err
+		}
+		pg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		view, err := filters.apply(pg)
+		if err != nil {
+			return err
+		}
+		return printMostUsedPackages(cmd.Context(), view, top)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rankCmd)
+
+	addGraphFlags(rankCmd)
+	addIntervalFlags(rankCmd)
+	addKindFlag(rankCmd)
+	addTopFlag(rankCmd)
+}
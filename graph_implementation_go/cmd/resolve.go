@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/spf13/cobra"
+)
+
+var (
+	asOfDate string
+	layers   bool
+)
+
+// resolveCmd represents the resolve command
+var resolveCmd = &cobra.Command{
+	Use:   "resolve",
+	Short: "Finds the latest dependencies of a package",
+	Long: `Finds the latest dependencies of a package (resolve). When --from and --to are given, only the packages
+released between the two dates are taken into account. When --kind is given, only the dependencies of the given
+kinds are followed. When --as-of is given, the dependencies are resolved the way a package manager would have
+resolved them on that date instead. When --layers is given, the resolved packages are printed in the order in
+which they can be built or installed: every line is a layer whose packages only depend on the previous layers, so
+they can be installed in parallel. The packages of a dependency cycle are joined by + and installed together.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filters, err := filtersFromFlags()
+		if err != nil {
+			return err
+		}
+		var asOf time.Time
+		if asOfDate != "" {
+			if asOf, err = time.Parse(dateLayout, asOfDate); err != nil {
+				return fmt.Errorf("--as-of must be in the format DD-MM-YYYY: %w", err)
+			}
+		}
+		pg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		key, err := keyFromFlags(pg)
+		if err != nil {
+			return err
+		}
+		view, err := filters.apply(pg)
+		if err != nil {
+			return err
+		}
+		var dependencies []g.NodeInfo
+		if asOfDate != "" {
+			dependencies, err = view.ResolveAsOf(key, asOf)
+		} else {
+			dependencies, err = view.LatestTransitiveDependencies(key)
+		}
+		if err != nil {
+			return err
+		}
+		if layers {
+			for i, layer := range view.InstallLayers(dependencies) {
+				fmt.Printf("Layer %d: %v\n", i+1, layer)
+			}
+			return nil
+		}
+		printNodes(&dependencies)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(resolveCmd)
+
+	addGraphFlags(resolveCmd)
+	addPackageFlags(resolveCmd)
+	addIntervalFlags(resolveCmd)
+	addKindFlag(resolveCmd)
+	resolveCmd.Flags().StringVar(&asOfDate, "as-of", "", "resolve the dependencies as they were on this date (DD-MM-YYYY)")
+	resolveCmd.Flags().BoolVar(&layers, "layers", false, "print the resolved packages in layers, in the order in which they can be installed")
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/AJMBrands/SoftwareThatMatters/server"
+	"github.com/spf13/cobra"
+)
+
+// shutdownTimeout is how long the server waits for the requests in progress when it is stopped.
+const shutdownTimeout = 10 * time.Second
+
+var addr string
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serves the queries on the graph over HTTP",
+	Long: `Loads the graph once and serves the queries on it over HTTP, so several people can query the same graph
+without creating it again. Every endpoint answers GET requests with JSON and takes the same arguments as the
+command of the same name, as query parameters:
+  /dependencies?package=lodash&version=4.17.20&from=01-01-2020&to=01-01-2021
+  /dependents?package=lodash&version=4.17.20
+  /resolve?package=lodash&version=4.17.20&as-of=01-01-2021
+  /between?from=01-01-2020&to=01-01-2021
+  /rank?top=10
+  /betweenness?top=10
+The server stops on Ctrl-C.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		httpServer := &http.Server{Addr: addr, Handler: server.New(cmd.Context(), pg)}
+		go func() {
+			<-cmd.Context().Done()
+			ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+			_ = httpServer.Shutdown(ctx)
+		}()
+		fmt.Printf("Serving the graph on %s\n", addr)
+		if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	addGraphFlags(serveCmd)
+	serveCmd.Flags().StringVar(&addr, "addr", ":8080", "address the server listens on")
+}
@@ -1,11 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"regexp"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -22,15 +22,15 @@ var startCmd = &cobra.Command{
 	Short: "Starts the application and ask guides you through the process of generating a graph",
 	Long:  `Starts the application and ask guides you through the process of generating a graph`,
 	Run: func(cmd *cobra.Command, args []string) {
-		start()
+		start(cmd.Context())
 	},
 }
 
 // start is the main function that starts the application. It asks the user for the data file and then generates the graph.
 // After the graph is generated, it asks the user how they want to proceed. The loop is done to allow the user to run
 // multiple requests on the same graph. This means that the graph can be generated once, and then it can be processed
-// multiple times.
-func start() {
+// multiple times. Cancelling ctx stops the long operations.
+func start(ctx context.Context) {
 	fileNames := getJSONFilesFromDataFolder()
 	if len(*fileNames) == 0 {
 		fmt.Println("No JSON files found in data folder! Make sure there is at least one file in the data/input folder.")
@@ -48,19 +48,30 @@ func start() {
 	}
 	path := "data/input/" + file
 
-	isUsingMaven := false
-
-	usingMavenPrompt := &survey.Confirm{
-		Message: "Is the packages data coming from Maven?",
+	dialectNames := make([]string, len(g.Dialects))
+	for i, d := range g.Dialects {
+		dialectNames[i] = d.Name()
+	}
+	dialectIndex := 0
+	dialectPrompt := &survey.Select{
+		Message: "Which versioning scheme does the packages data use?",
+		Options: dialectNames,
 	}
-	err = survey.AskOne(usingMavenPrompt, &isUsingMaven)
+	err = survey.AskOne(dialectPrompt, &dialectIndex)
 
 	fmt.Println("Creating the graph. This may take a while!")
 	if err != nil {
 		panic(err)
 	}
 
-	graph, hashMap, idToNodeInfo := g.CreateGraph(path, isUsingMaven)
+	progress := newProgressBar(os.Stderr)
+	pg, report, err := g.CreateGraph(ctx, g.BuildOptions{Mode: g.BestEffort, Progress: progress}, g.Input{Path: path, Dialect: g.Dialects[dialectIndex]})
+	progress.finish()
+	if err != nil {
+		fmt.Println("The graph could not be created:", err)
+		return
+	}
+	fmt.Println(report)
 
 	stop := false
 	for !stop {
@@ -76,6 +87,8 @@ func start() {
 				"Find the n most used packages",
 				"Find the n most used packages between two time stamps",
 				"Find the n nodes with the highest betweenness",
+				"Find all the packages that depend on a package",
+				"Find the dependencies of a package as they were resolved on a date",
 				"Quit",
 			},
 		}
@@ -87,41 +100,30 @@ func start() {
 
 		switch operationIndex {
 		case 0:
-			nodes := findAllPackagesBetweenTwoTimestamps(idToNodeInfo)
-			for _, node := range *nodes {
-				fmt.Println(node)
-			}
+			printNodesOrError(findAllPackagesBetweenTwoTimestamps(pg))
 		case 1:
-			name := generateAndRunPackageNamePrompt("Please input the package name", idToNodeInfo)
-			nodes := g.GetTransitiveDependenciesNode(graph, idToNodeInfo, hashMap, name)
-			for _, node := range *nodes {
-				fmt.Println(node)
-			}
-
+			key := generateAndRunPackageNamePrompt("Please input the package name", pg)
+			dependencies, err := pg.TransitiveDependencies(key)
+			printNodesOrError(&dependencies, err)
 		case 2:
-			nodes := findAllDependenciesOfAPackageBetweenTwoTimestamps(graph, hashMap, idToNodeInfo)
-			for _, node := range *nodes {
-				fmt.Println(node)
-			}
+			printNodesOrError(findAllDependenciesOfAPackageBetweenTwoTimestamps(pg))
 		case 3:
-			nodes := findLatestDependenciesOfAPackage(graph, hashMap, idToNodeInfo)
-			for _, node := range *nodes {
-				fmt.Println(node)
-			}
-
+			printNodesOrError(findLatestDependenciesOfAPackage(pg))
 		case 4:
-			nodes := findLatestDependenciesOfAPackageBetweenTwoTimestamps(graph, hashMap, idToNodeInfo)
-
-			for _, node := range *nodes {
-				fmt.Println(node)
-			}
+			printNodesOrError(findLatestDependenciesOfAPackageBetweenTwoTimestamps(pg))
 		case 5:
-			findMostUsedPackages(graph, idToNodeInfo, false)
+			findMostUsedPackages(ctx, pg, false)
 		case 6:
-			findMostUsedPackages(graph, idToNodeInfo, true)
+			findMostUsedPackages(ctx, pg, true)
 		case 7:
-			findMostUsedPackagesUsingBetweenness(graph, idToNodeInfo)
+			findMostUsedPackagesUsingBetweenness(ctx, pg)
 		case 8:
+			key := generateAndRunPackageNamePrompt("Please input the package name", pg)
+			dependents, err := pg.TransitiveDependents(key)
+			printNodesOrError(&dependents, err)
+		case 9:
+			printNodesOrError(findDependenciesOfAPackageAsOfADate(pg))
+		case 10:
 			fmt.Println("Stopping the program...")
 			stop = true
 		}
@@ -130,51 +132,41 @@ func start() {
 
 }
 
-func findMostUsedPackages(graph *g.DirectedGraph, idToNodeInfo map[int64]g.NodeInfo, betweenTimestamps bool) {
+// printNodesOrError prints the nodes, or the error of the query that returned them. The interactive loop goes on
+// after an error, so the user can try another query.
+func printNodesOrError(nodes *[]g.NodeInfo, err error) {
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	printNodes(nodes)
+}
+
+func findMostUsedPackages(ctx context.Context, pg *g.PackageGraph, betweenTimestamps bool) {
 	if betweenTimestamps {
 		beginTime := generateAndRunDatePrompt("Please input the beginning date of the interval (DD-MM-YYYY)")
 		endTime := generateAndRunDatePrompt("Please input the end date of the interval (DD-MM-YYYY)")
 		fmt.Println("Getting the latest dependencies for packages. This will take a while")
-		t1 := time.Now().Unix()
-		g.FilterNoTraversal(graph, idToNodeInfo, beginTime, endTime)
-		t2 := time.Now().Unix()
-		fmt.Printf("Graph filtering took %d seconds\n", t2-t1)
-	}
-
-	fmt.Println("Running PageRank")
-	pr := g.PageRank(graph)
-	keys := make([]int64, 0, len(pr))
-	aggregated := make(map[string]float64)
-
-	for k, value := range pr {
-		keys = append(keys, k)
-		aggregated[idToNodeInfo[k].Name] += value
-	}
-
-	aggregatedKeys := make([]string, 0, len(aggregated))
-
-	for k := range aggregated {
-		aggregatedKeys = append(aggregatedKeys, k)
+		view, err := filterBetween(pg, beginTime, endTime)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		pg = view
 	}
 
-	sort.SliceStable(aggregatedKeys, func(i, j int) bool {
-		return aggregated[aggregatedKeys[i]] > aggregated[aggregatedKeys[j]]
-	})
-
-	sort.SliceStable(keys, func(i, j int) bool {
-		return pr[keys[i]] > pr[keys[j]]
-	})
-
 	count := generateAndRunNumberPrompt("Please select the number (n > 0) of highest-ranked packages you wish to see")
-	for i := 0; i < count; i++ {
-		fmt.Printf("The %d-th highest-ranked node (%v) has rank %f \n", i, idToNodeInfo[keys[i]], pr[keys[i]])
+	if err := printMostUsedPackages(ctx, pg, count); err != nil {
+		fmt.Println(err)
 	}
+}
 
-	fmt.Print("\n---------------------------------------------\n\n")
-
-	for i := 0; i < count; i++ {
-		fmt.Printf("The %d-th highest-ranked package (%v) has rank %f \n", i, aggregatedKeys[i], aggregated[aggregatedKeys[i]])
-	}
+// inputFileSuffixes lists the suffixes of the files that getJSONFilesFromDataFolder returns: JSON documents and
+// newline-delimited JSON, possibly compressed with gzip or zstd.
+var inputFileSuffixes = []string{
+	".json", ".json.gz", ".json.zst",
+	".ndjson", ".ndjson.gz", ".ndjson.zst",
+	".jsonl", ".jsonl.gz", ".jsonl.zst",
 }
 
 // getJSONFilesFromDataFolder returns a slice of strings with the names of the JSON files in the data folder. It can
@@ -197,71 +189,63 @@ func getJSONFilesFromDataFolder() *[]string {
 	}
 	var fileNames []string
 	for _, file := range files {
-		if strings.HasSuffix(file.Name(), ".json") {
-			fileNames = append(fileNames, file.Name())
+		for _, suffix := range inputFileSuffixes {
+			if strings.HasSuffix(file.Name(), suffix) {
+				fileNames = append(fileNames, file.Name())
+				break
+			}
 		}
-
 	}
 	return &fileNames
 }
 
-func findAllPackagesBetweenTwoTimestamps(idToNodeInfo map[int64]g.NodeInfo) *[]g.NodeInfo {
+func findAllPackagesBetweenTwoTimestamps(pg *g.PackageGraph) (*[]g.NodeInfo, error) {
 	beginTime := generateAndRunDatePrompt("Please input the beginning date of the interval (DD-MM-YYYY)")
 	endTime := generateAndRunDatePrompt("Please input the end date of the interval (DD-MM-YYYY)")
-
-	var nodesInInterval []g.NodeInfo
-
-	for _, node := range idToNodeInfo {
-		nodeTime, err := time.Parse(time.RFC3339, node.Timestamp)
-		if err != nil {
-			fmt.Println("There was an error parsing the timestamps in the nodes!")
-			panic(err)
-		}
-		if g.InInterval(nodeTime, beginTime, endTime) {
-			nodesInInterval = append(nodesInInterval, node)
-		}
-	}
-
-	return &nodesInInterval
-
+	return findAllPackagesBetween(pg, beginTime, endTime)
 }
 
-func findAllDependenciesOfAPackageBetweenTwoTimestamps(graph *g.DirectedGraph, hashMap map[uint64]int64, nodeMap map[int64]g.NodeInfo) *[]g.NodeInfo {
+func findAllDependenciesOfAPackageBetweenTwoTimestamps(pg *g.PackageGraph) (*[]g.NodeInfo, error) {
 	beginTime := generateAndRunDatePrompt("Please input the beginning date of the interval (DD-MM-YYYY)")
 	endTime := generateAndRunDatePrompt("Please input the end date of the interval (DD-MM-YYYY)")
-	nodeStringId := generateAndRunPackageNamePrompt("Please select the name and the version of the package", nodeMap)
-	g.FilterNoTraversal(graph, nodeMap, beginTime, endTime)
-	return g.GetTransitiveDependenciesNode(graph, nodeMap, hashMap, nodeStringId)
+	key := generateAndRunPackageNamePrompt("Please select the name and the version of the package", pg)
+	view, err := filterBetween(pg, beginTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	dependencies, err := view.TransitiveDependencies(key)
+	return &dependencies, err
 }
 
-func findLatestDependenciesOfAPackage(graph *g.DirectedGraph, hashMap map[uint64]int64, nodeMap map[int64]g.NodeInfo) *[]g.NodeInfo {
-	nodeStringId := generateAndRunPackageNamePrompt("Please select the name and the version of the package", nodeMap)
-	return g.GetLatestTransitiveDependenciesNode(graph, nodeMap, hashMap, nodeStringId)
+func findLatestDependenciesOfAPackage(pg *g.PackageGraph) (*[]g.NodeInfo, error) {
+	key := generateAndRunPackageNamePrompt("Please select the name and the version of the package", pg)
+	dependencies, err := pg.LatestTransitiveDependencies(key)
+	return &dependencies, err
 }
 
-func findLatestDependenciesOfAPackageBetweenTwoTimestamps(graph *g.DirectedGraph, hashMap map[uint64]int64, nodeMap map[int64]g.NodeInfo) *[]g.NodeInfo {
+func findLatestDependenciesOfAPackageBetweenTwoTimestamps(pg *g.PackageGraph) (*[]g.NodeInfo, error) {
 	beginTime := generateAndRunDatePrompt("Please input the beginning date of the interval (DD-MM-YYYY)")
 	endTime := generateAndRunDatePrompt("Please input the end date of the interval (DD-MM-YYYY)")
-	nodeStringId := generateAndRunPackageNamePrompt("Please select the name and the version of the package", nodeMap)
-	g.FilterNoTraversal(graph, nodeMap, beginTime, endTime)
-	return g.GetLatestTransitiveDependenciesNode(graph, nodeMap, hashMap, nodeStringId)
-}
-
-func findMostUsedPackagesUsingBetweenness(graph *g.DirectedGraph, idToNodeInfo map[int64]g.NodeInfo) {
-	fmt.Println("Running betweenness algorithm")
-	betweenness := g.Betweenness(graph)
-	keys := make([]int64, 0, len(betweenness))
-	for k := range betweenness {
-		keys = append(keys, k)
+	key := generateAndRunPackageNamePrompt("Please select the name and the version of the package", pg)
+	view, err := filterBetween(pg, beginTime, endTime)
+	if err != nil {
+		return nil, err
 	}
+	dependencies, err := view.LatestTransitiveDependencies(key)
+	return &dependencies, err
+}
 
-	sort.SliceStable(keys, func(i, j int) bool {
-		return betweenness[keys[i]] > betweenness[keys[j]]
-	})
+func findDependenciesOfAPackageAsOfADate(pg *g.PackageGraph) (*[]g.NodeInfo, error) {
+	asOf := generateAndRunDatePrompt("Please input the date at which the package is resolved (DD-MM-YYYY)")
+	key := generateAndRunPackageNamePrompt("Please select the name and the version of the package", pg)
+	dependencies, err := pg.ResolveAsOf(key, asOf)
+	return &dependencies, err
+}
 
+func findMostUsedPackagesUsingBetweenness(ctx context.Context, pg *g.PackageGraph) {
 	count := generateAndRunNumberPrompt("Please select the number (n > 0) of highest-ranked packages you wish to see")
-	for i := 0; i < count; i++ {
-		fmt.Printf("The %d-th highest-ranked node (%v) has a betweenness score of %f \n", i, idToNodeInfo[keys[i]], betweenness[keys[i]])
+	if err := printHighestBetweenness(ctx, pg, count); err != nil {
+		fmt.Println(err)
 	}
 }
 
@@ -301,7 +285,7 @@ func generateAndRunDatePrompt(message string) time.Time {
 			return errors.New("input must be in the format: DD-MM-YYYY")
 		}
 		if len(str) == 10 {
-			_, err := time.Parse("02-01-2006", str)
+			_, err := time.Parse(dateLayout, str)
 			if err != nil {
 				return errors.New("input must be a valid date")
 			}
@@ -319,16 +303,19 @@ func generateAndRunDatePrompt(message string) time.Time {
 		panic(err)
 	}
 
-	timestamp, _ := time.Parse("02-01-2006", timeString)
+	timestamp, _ := time.Parse(dateLayout, timeString)
 	return timestamp
 
 }
 
-func generateAndRunPackageNamePrompt(message string, nodeMap map[int64]g.NodeInfo) string {
-	names := make([]string, 0, len(nodeMap))
-	for _, node := range nodeMap {
+func generateAndRunPackageNamePrompt(message string, pg *g.PackageGraph) g.NodeKey {
+	nodes := pg.Nodes()
+	names := make([]string, 0, len(nodes))
+	keys := make(map[string]g.NodeKey, len(nodes))
+	for _, node := range nodes {
 		name := fmt.Sprintf("%s-%s", node.Name, node.Version)
 		names = append(names, name)
+		keys[name] = node.Key()
 	}
 	packagePrompt := &survey.Select{
 		Message: message,
@@ -342,7 +329,7 @@ func generateAndRunPackageNamePrompt(message string, nodeMap map[int64]g.NodeInf
 		panic(err)
 	}
 
-	return packageID
+	return keys[packageID]
 }
 
 func init() {
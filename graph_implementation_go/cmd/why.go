@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/spf13/cobra"
+)
+
+var (
+	allPaths      bool
+	maxPaths      int
+	maxPathLength int
+)
+
+// whyCmd represents the why command
+var whyCmd = &cobra.Command{
+	Use:   "why <root> <target>",
+	Short: "Explains why a package depends on another package",
+	Long: `Explains why a package version (root, given as name@version) depends on any version of another package
+(target, given by its name). It prints a shortest path from the root to a version of the target, with the constraint
+of every hop. When --all is given, the paths through which the root depends on the target are all printed, up to
+--max-paths of them. When --from and --to are given, only the packages released between the two dates are taken
+into account. When --kind is given, only the dependencies of the given kinds are followed.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := packageFromArg(args[0]); err != nil {
+			return err
+		}
+		if maxPaths <= 0 || maxPathLength < 0 {
+			return errors.New("--max-paths must be larger than 0 and --max-length cannot be negative")
+		}
+		filters, err := filtersFromFlags()
+		if err != nil {
+			return err
+		}
+		pg, err := loadGraph(cmd)
+		if err != nil {
+			return err
+		}
+		key, err := keyFromFlags(pg)
+		if err != nil {
+			return err
+		}
+		view, err := filters.apply(pg)
+		if err != nil {
+			return err
+		}
+		paths, err := view.WhyDepends(key, args[1], g.WhyOptions{All: allPaths, MaxPaths: maxPaths, MaxLength: maxPathLength})
+		if err != nil {
+			return err
+		}
+		if len(paths) == 0 {
+			fmt.Printf("%v does not depend on %s\n", key, args[1])
+			return nil
+		}
+		for _, path := range paths {
+			fmt.Println(path)
+		}
+		if allPaths && len(paths) == maxPaths {
+			fmt.Printf("Only the first %d paths are shown, use --max-paths to show more\n", maxPaths)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(whyCmd)
+
+	addGraphFlags(whyCmd)
+	addEcosystemFlag(whyCmd)
+	addIntervalFlags(whyCmd)
+	addKindFlag(whyCmd)
+	whyCmd.Flags().BoolVar(&allPaths, "all", false, "show all the paths instead of a shortest one")
+	whyCmd.Flags().IntVar(&maxPaths, "max-paths", g.DefaultMaxPaths, "number (n > 0) of paths shown at most with --all")
+	whyCmd.Flags().IntVar(&maxPathLength, "max-length", 0, "number of dependencies of the longest path shown, any length when 0")
+}
@@ -0,0 +1,207 @@
+// Copyright ©2015 The Gonum Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the third_party_licenses/Gonum-LICENSE file.
+
+// This code was modified in order to better suit the needs of this repository. PageRank and Betweenness are ports of
+// PageRankSparse and Betweenness from gonum/graph/network that can be cancelled through their context and report their
+// progress. PageRank starts from the uniform vector instead of a random one, so its result is deterministic.
+
+package graph
+
+import (
+	"context"
+	"math"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+const (
+	// pageRankDamping is the damping factor of PageRank
+	pageRankDamping = 0.85
+	// pageRankTolerance is the 2-norm of the difference between two iterations under which PageRank stops
+	pageRankTolerance = 0.001
+)
+
+// PageRank uses the sparse page rank algorithm to find the Page ranks of all nodes. Every iteration is reported to
+// progress, whose total is 0 since the number of iterations is not known in advance. It stops with the error of ctx
+// when ctx is cancelled.
+func PageRank(ctx context.Context, g graph.Directed, progress ProgressReporter) (map[int64]float64, error) {
+	// PageRank is implemented according to "How Google Finds Your Needle in the Web's Haystack".
+	//
+	// G.I^k = alpha.H.I^k + alpha.A.I^k + (1-alpha).1/n.1.I^k
+	//
+	// http://www.ams.org/samplings/feature-column/fcarc-pagerank
+
+	nodes := graph.NodesOf(g.Nodes())
+	indexOf := make(map[int64]int, len(nodes))
+	for i, n := range nodes {
+		indexOf[n.ID()] = i
+	}
+
+	m := make([]compressedRow, len(nodes))
+	var dangling compressedRow
+	df := pageRankDamping / float64(len(nodes))
+	for j, u := range nodes {
+		if j%1024 == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		to := graph.NodesOf(g.From(u.ID()))
+		f := pageRankDamping / float64(len(to))
+		for _, v := range to {
+			m[indexOf[v.ID()]].addTo(j, f)
+		}
+		if len(to) == 0 {
+			dangling.addTo(j, df)
+		}
+	}
+
+	last := make([]float64, len(nodes))
+	vec := make([]float64, len(nodes))
+	for i := range vec {
+		vec[i] = 1 / float64(len(nodes))
+	}
+
+	dt := (1 - pageRankDamping) / float64(len(nodes))
+	for iteration := 1; ; iteration++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		last, vec = vec, last
+
+		for i, r := range m { // First term of the G matrix equation;
+			vec[i] = r.dot(last)
+		}
+		with := dangling.dot(last) // Second term;
+		var away float64           // Last term.
+		for _, f := range last {
+			away += dt * f
+		}
+
+		for i := range vec {
+			vec[i] += with + away
+		}
+		reportProgress(progress, StagePageRank, iteration, 0)
+		if normDiff(vec, last) < pageRankTolerance {
+			break
+		}
+	}
+
+	ranks := make(map[int64]float64, len(nodes))
+	for i, r := range vec {
+		ranks[nodes[i].ID()] = r
+	}
+	return ranks, nil
+}
+
+// compressedRow is a row of a sparse matrix.
+type compressedRow []sparseElement
+
+// addTo adds the value v to the vector element at j. Repeated calls to addTo with the same vector index will result
+// in non-unique element representation.
+func (r *compressedRow) addTo(j int, v float64) {
+	*r = append(*r, sparseElement{index: j, value: v})
+}
+
+// dot returns the dot product of the row and v.
+func (r compressedRow) dot(v []float64) float64 {
+	var sum float64
+	for _, e := range r {
+		sum += v[e.index] * e.value
+	}
+	return sum
+}
+
+// sparseElement is a sparse vector or matrix element.
+type sparseElement struct {
+	index int
+	value float64
+}
+
+// normDiff returns the 2-norm of the difference between x and y.
+func normDiff(x, y []float64) float64 {
+	var sum float64
+	for i, v := range x {
+		d := v - y[i]
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+// Betweenness returns the betweenness centrality of the nodes of g with Brandes' algorithm. Nodes with a betweenness
+// of 0 are left out. Every node whose shortest paths were counted is reported to progress. It stops with the error of
+// ctx when ctx is cancelled.
+func Betweenness(ctx context.Context, g graph.Directed, progress ProgressReporter) (map[int64]float64, error) {
+	// Brandes' algorithm for finding betweenness centrality for nodes in an unweighted graph:
+	//
+	// http://www.inf.uni-konstanz.de/algo/publications/b-fabc-01.pdf
+
+	nodes := graph.NodesOf(g.Nodes())
+	indexOf := make(map[int64]int, len(nodes))
+	for i, n := range nodes {
+		indexOf[n.ID()] = i
+	}
+
+	var (
+		cb    = make([]float64, len(nodes))
+		p     = make([][]int, len(nodes))
+		sigma = make([]float64, len(nodes))
+		d     = make([]int, len(nodes))
+		delta = make([]float64, len(nodes))
+		stack []int
+		queue []int
+	)
+	for s := range nodes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		stack = stack[:0]
+		for i := range nodes {
+			p[i] = p[i][:0]
+			sigma[i] = 0
+			d[i] = -1
+			delta[i] = 0
+		}
+		sigma[s] = 1
+		d[s] = 0
+
+		queue = append(queue[:0], s)
+		for len(queue) != 0 {
+			v := queue[0]
+			queue = queue[1:]
+			stack = append(stack, v)
+			for to := g.From(nodes[v].ID()); to.Next(); {
+				w := indexOf[to.Node().ID()]
+				// w found for the first time?
+				if d[w] < 0 {
+					queue = append(queue, w)
+					d[w] = d[v] + 1
+				}
+				// shortest path to w via v?
+				if d[w] == d[v]+1 {
+					sigma[w] += sigma[v]
+					p[w] = append(p[w], v)
+				}
+			}
+		}
+
+		// The stack returns vertices in order of non-increasing distance from s
+		for i := len(stack) - 1; i >= 0; i-- {
+			w := stack[i]
+			for _, v := range p[w] {
+				delta[v] += sigma[v] / sigma[w] * (1 + delta[w])
+			}
+			if w != s {
+				cb[w] += delta[w]
+			}
+		}
+		reportProgress(progress, StageBetweenness, s+1, len(nodes))
+	}
+
+	betweenness := make(map[int64]float64)
+	for i, b := range cb {
+		if b != 0 {
+			betweenness[nodes[i].ID()] = b
+		}
+	}
+	return betweenness, nil
+}
@@ -0,0 +1,66 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/graph/network"
+)
+
+func TestCentralityMatchesGonum(t *testing.T) {
+	graph, _, _ := createDependentsTestGraph()
+
+	var iterations int
+	ranks, err := PageRank(context.Background(), graph, ProgressFunc(func(stage string, done, total int) {
+		if stage != StagePageRank || total != 0 {
+			t.Errorf("Unexpected progress %s %d/%d", stage, done, total)
+		}
+		iterations = done
+	}))
+	if err != nil || iterations == 0 {
+		t.Fatalf("PageRank failed after %d iterations: %v", iterations, err)
+	}
+	for id, expected := range network.PageRankSparse(graph, 0.85, 0.001) {
+		if math.Abs(ranks[id]-expected) > 0.01 {
+			t.Errorf("Expected a rank of %f for %d, got %f", expected, id, ranks[id])
+		}
+	}
+
+	var done, total int
+	betweenness, err := Betweenness(context.Background(), graph, ProgressFunc(func(stage string, d, t int) {
+		done, total = d, t
+	}))
+	if err != nil || done != total || total != graph.Nodes().Len() {
+		t.Fatalf("Betweenness failed at %d/%d: %v", done, total, err)
+	}
+	expected := network.Betweenness(graph)
+	if len(betweenness) != len(expected) {
+		t.Errorf("Expected %v, got %v", expected, betweenness)
+	}
+	for id, b := range expected {
+		if math.Abs(betweenness[id]-b) > 1e-9 {
+			t.Errorf("Expected a betweenness of %f for %d, got %f", b, id, betweenness[id])
+		}
+	}
+}
+
+func TestCancelledOperations(t *testing.T) {
+	graph, _, _ := createDependentsTestGraph()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := PageRank(ctx, graph, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected PageRank to be cancelled, got %v", err)
+	}
+	if _, err := Betweenness(ctx, graph, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected Betweenness to be cancelled, got %v", err)
+	}
+	packagesInfo := createWorkersTestInput()
+	packagesGraph := NewDirectedGraph()
+	index, nodeMap := CreateMaps(&packagesInfo, packagesGraph)
+	if _, err := CreateEdges(ctx, packagesGraph, &packagesInfo, index, nodeMap, NpmDialect); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected CreateEdges to be cancelled, got %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/topo"
+)
+
+// CycleReport lists the dependency cycles of a graph. The versions of a set of packages often depend on each other in
+// the same way, so the cycles between the same packages are grouped together.
+type CycleReport struct {
+	// Components is the number of strongly connected components of the graph that contain a cycle
+	Components int `json:"components"`
+	// Cycles lists the groups of packages that depend on each other, with the most packages first
+	Cycles []PackageCycle `json:"cycles"`
+}
+
+// PackageCycle is a group of packages of an ecosystem whose versions depend on each other, directly or transitively.
+type PackageCycle struct {
+	Ecosystem string         `json:"ecosystem"`
+	Packages  []CyclePackage `json:"packages"`
+	// Components is the number of strongly connected components made of versions of these packages
+	Components int `json:"components"`
+}
+
+// CyclePackage is a package of a PackageCycle, with the versions of it that are part of the cycles.
+type CyclePackage struct {
+	Name     string   `json:"name"`
+	Versions []string `json:"versions"`
+}
+
+func (report CycleReport) String() string {
+	if len(report.Cycles) == 0 {
+		return "No dependency cycles found"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d dependency cycles between %d groups of packages", report.Components, len(report.Cycles))
+	for _, cycle := range report.Cycles {
+		b.WriteString("\n")
+		b.WriteString(cycle.String())
+	}
+	return b.String()
+}
+
+func (cycle PackageCycle) String() string {
+	packages := make([]string, len(cycle.Packages))
+	for i, pkg := range cycle.Packages {
+		packages[i] = fmt.Sprintf("%s (%s)", pkg.Name, strings.Join(pkg.Versions, ", "))
+	}
+	prefix := ""
+	if cycle.Ecosystem != "" {
+		prefix = cycle.Ecosystem + ": "
+	}
+	if cycle.Components == 1 {
+		return fmt.Sprintf("%s%s - 1 cycle", prefix, strings.Join(packages, ", "))
+	}
+	return fmt.Sprintf("%s%s - %d cycles", prefix, strings.Join(packages, ", "), cycle.Components)
+}
+
+// stronglyConnectedComponents returns the strongly connected components of g in reverse topological order, so every
+// component comes after the components it depends on. The ids of every component are sorted.
+func stronglyConnectedComponents(g graph.Directed) [][]int64 {
+	components := topo.TarjanSCC(g)
+	result := make([][]int64, len(components))
+	for i, component := range components {
+		ids := make([]int64, len(component))
+		for j, node := range component {
+			ids[j] = node.ID()
+		}
+		sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+		result[i] = ids
+	}
+	return result
+}
+
+// isCyclic returns whether the strongly connected component contains a cycle, which is when it has more than one node
+// or its node depends on itself.
+func isCyclic(g graph.Directed, component []int64) bool {
+	return len(component) > 1 || g.HasEdgeFromTo(component[0], component[0])
+}
+
+// CyclicComponents returns the strongly connected components of g that contain a cycle. The versions of every
+// component are sorted by name and version, and the components are sorted by their first version.
+func CyclicComponents(g graph.Directed, nodeMap map[int64]NodeInfo) [][]NodeInfo {
+	var result [][]NodeInfo
+	for _, component := range stronglyConnectedComponents(g) {
+		if !isCyclic(g, component) {
+			continue
+		}
+		nodes := make([]NodeInfo, len(component))
+		for i, id := range component {
+			nodes[i] = nodeMap[id]
+		}
+		sort.Slice(nodes, func(i, j int) bool { return lessNodeInfo(nodes[i], nodes[j]) })
+		result = append(result, nodes)
+	}
+	sort.Slice(result, func(i, j int) bool { return lessNodeInfo(result[i][0], result[j][0]) })
+	return result
+}
+
+// lessNodeInfo orders the nodes by ecosystem, name and version.
+func lessNodeInfo(a, b NodeInfo) bool {
+	if a.Ecosystem != b.Ecosystem {
+		return a.Ecosystem < b.Ecosystem
+	}
+	if a.Name != b.Name {
+		return a.Name < b.Name
+	}
+	return a.Version < b.Version
+}
+
+// ReportCycles groups the cyclic components, as returned by CyclicComponents, by the packages they are made of.
+// The versions of every package are sorted from the lowest to the highest, as ordered by the dialects of the index.
+func ReportCycles(index *NodeIndex, components [][]NodeInfo) *CycleReport {
+	type group struct {
+		cycle    *PackageCycle
+		versions map[string][]NodeInfo
+	}
+	groups := make(map[string]*group)
+	var order []string
+	for _, component := range components {
+		versions := make(map[string][]NodeInfo)
+		var names []string
+		for _, node := range component {
+			if _, ok := versions[node.Name]; !ok {
+				names = append(names, node.Name)
+			}
+			versions[node.Name] = append(versions[node.Name], node)
+		}
+		sort.Strings(names)
+		key := component[0].Ecosystem + ":" + strings.Join(names, "\x00")
+
+		current, ok := groups[key]
+		if !ok {
+			current = &group{cycle: &PackageCycle{Ecosystem: component[0].Ecosystem}, versions: make(map[string][]NodeInfo)}
+			for _, name := range names {
+				current.cycle.Packages = append(current.cycle.Packages, CyclePackage{Name: name})
+			}
+			groups[key] = current
+			order = append(order, key)
+		}
+		current.cycle.Components++
+		for name, nodes := range versions {
+			current.versions[name] = append(current.versions[name], nodes...)
+		}
+	}
+
+	report := &CycleReport{Components: len(components), Cycles: make([]PackageCycle, 0, len(order))}
+	for _, key := range order {
+		current := groups[key]
+		for i := range current.cycle.Packages {
+			pkg := &current.cycle.Packages[i]
+			nodes := current.versions[pkg.Name]
+			sort.Slice(nodes, func(a, b int) bool { return isNewerVersion(index, nodes[b], nodes[a]) })
+			for _, node := range nodes {
+				pkg.Versions = append(pkg.Versions, node.Version)
+			}
+		}
+		report.Cycles = append(report.Cycles, *current.cycle)
+	}
+	sort.SliceStable(report.Cycles, func(i, j int) bool {
+		a, b := report.Cycles[i], report.Cycles[j]
+		if len(a.Packages) != len(b.Packages) {
+			return len(a.Packages) > len(b.Packages)
+		}
+		return a.Components > b.Components
+	})
+	return report
+}
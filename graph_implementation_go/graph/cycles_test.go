@@ -0,0 +1,75 @@
+package graph
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func createCyclesTestGraph() *PackageGraph {
+	version := func(dependencies map[string]string) VersionInfo {
+		return VersionInfo{Timestamp: "2021-01-01T00:00:00Z", Dependencies: dependencies}
+	}
+	packagesInfo := []PackageInfo{
+		{Name: "a", Versions: map[string]VersionInfo{
+			"1.0.0": version(map[string]string{"b": "1.0.0"}),
+			"2.0.0": version(map[string]string{"b": "2.0.0"}),
+		}},
+		{Name: "b", Versions: map[string]VersionInfo{
+			"1.0.0": version(map[string]string{"a": "1.0.0"}),
+			"2.0.0": version(map[string]string{"a": "2.0.0"}),
+		}},
+		{Name: "c", Versions: map[string]VersionInfo{"1.0.0": version(map[string]string{"d": "1.0.0"})}},
+		{Name: "d", Versions: map[string]VersionInfo{"1.0.0": version(map[string]string{"e": "1.0.0"})}},
+		{Name: "e", Versions: map[string]VersionInfo{"1.0.0": version(map[string]string{"c": "1.0.0"})}},
+		{Name: "f", Versions: map[string]VersionInfo{"1.0.0": version(map[string]string{"a": "1.0.0"})}},
+	}
+	graph := NewDirectedGraph()
+	index, nodeMap := CreateMaps(&packagesInfo, graph)
+	CreateEdges(context.Background(), graph, &packagesInfo, index, nodeMap, SemverDialect)
+	return NewPackageGraph(graph, index, nodeMap)
+}
+
+func TestCycles(t *testing.T) {
+	pg := createCyclesTestGraph()
+
+	components := CyclicComponents(pg.Directed(), pg.nodes)
+	var keys [][]string
+	for _, component := range components {
+		var componentKeys []string
+		for _, node := range component {
+			componentKeys = append(componentKeys, node.Key().String())
+		}
+		keys = append(keys, componentKeys)
+	}
+	expectedKeys := [][]string{{"a@1.0.0", "b@1.0.0"}, {"a@2.0.0", "b@2.0.0"}, {"c@1.0.0", "d@1.0.0", "e@1.0.0"}}
+	if !reflect.DeepEqual(keys, expectedKeys) {
+		t.Errorf("Expected the components %v, got %v", expectedKeys, keys)
+	}
+
+	report := pg.Cycles()
+	expected := &CycleReport{
+		Components: 3,
+		Cycles: []PackageCycle{
+			{Packages: []CyclePackage{{"c", []string{"1.0.0"}}, {"d", []string{"1.0.0"}}, {"e", []string{"1.0.0"}}}, Components: 1},
+			{Packages: []CyclePackage{{"a", []string{"1.0.0", "2.0.0"}}, {"b", []string{"1.0.0", "2.0.0"}}}, Components: 2},
+		},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, report)
+	}
+	expectedText := `3 dependency cycles between 2 groups of packages
+c (1.0.0), d (1.0.0), e (1.0.0) - 1 cycle
+a (1.0.0, 2.0.0), b (1.0.0, 2.0.0) - 2 cycles`
+	if report.String() != expectedText {
+		t.Errorf("Expected the text\n%s\ngot\n%s", expectedText, report)
+	}
+
+	view, err := pg.Filter(OfKinds(Dev))
+	if err != nil {
+		t.Fatalf("Filtering failed: %v", err)
+	}
+	if report := view.Cycles(); report.Components != 0 || len(report.Cycles) != 0 {
+		t.Errorf("Expected no cycles without runtime dependencies, got %v", report)
+	}
+}
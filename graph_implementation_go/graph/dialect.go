@@ -0,0 +1,196 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+// Version is a version that was parsed by a Dialect.
+type Version interface {
+	String() string
+}
+
+// Constraint is a version constraint that was parsed by a Dialect.
+type Constraint interface {
+	// Check returns whether the version satisfies the constraint. The version must have been parsed by the same
+	// dialect as the constraint.
+	Check(v Version) bool
+	// String returns the constraint that is actually checked, which is stored as the translated constraint of edges.
+	String() string
+}
+
+// Dialect implements the syntax and the semantics of the versions and the constraints of an ecosystem. CreateEdges
+// uses it to decide which versions of a dependency satisfy the constraint of a package.
+type Dialect interface {
+	Name() string
+	ParseVersion(version string) (Version, error)
+	ParseConstraint(constraint string) (Constraint, error)
+	// Compare returns -1, 0 or 1 when a is respectively lower than, equal to or greater than b.
+	Compare(a, b Version) int
+}
+
+var (
+	// SemverDialect follows Semantic Versioning, with the constraint syntax of Masterminds/semver.
+	SemverDialect Dialect = semverDialect{}
+	// MavenDialect follows the version ordering and the version ranges of Maven.
+	MavenDialect Dialect = mavenDialect{}
+	// PEP440Dialect follows PEP 440, which is used by PyPI.
+	PEP440Dialect Dialect = pep440Dialect{}
+	// NpmDialect follows the ranges of node-semver and the dependency specifications of npm.
+	NpmDialect Dialect = npmDialect{}
+)
+
+// Dialects lists all the supported dialects.
+var Dialects = []Dialect{SemverDialect, MavenDialect, PEP440Dialect, NpmDialect}
+
+// SpecDialect is implemented by the dialects whose dependency specifications can do more than constrain the versions
+// of the dependency, such as naming a dist-tag or a git repository.
+type SpecDialect interface {
+	Dialect
+	ParseSpec(name, spec string) (DependencySpec, error)
+}
+
+// DependencySpec is a parsed dependency specification. When Source is RegistrySource, the dependency is resolved to
+// the versions of the package Name that satisfy Constraint or, when Tag is set, to the version that the dist-tag points
+// to. Other sources do not point to versions of the input, so they are kept as external nodes.
+type DependencySpec struct {
+	// Name is the package whose versions are selected. It differs from the name of the dependency for aliases
+	Name       string
+	Constraint Constraint
+	Tag        string
+	Source     DependencySource
+}
+
+// parseDependencySpec parses the specification of the dependency on the package name. Dialects that do not implement
+// SpecDialect only accept constraints.
+func parseDependencySpec(dialect Dialect, name, spec string) (DependencySpec, error) {
+	if sd, ok := dialect.(SpecDialect); ok {
+		return sd.ParseSpec(name, spec)
+	}
+	constraint, err := dialect.ParseConstraint(spec)
+	if err != nil {
+		return DependencySpec{}, err
+	}
+	return DependencySpec{Name: name, Constraint: constraint}, nil
+}
+
+// DialectByName returns the dialect with the given name. PyPI is accepted as an alias of PEP 440.
+func DialectByName(name string) (Dialect, error) {
+	if strings.EqualFold(name, "pypi") {
+		return PEP440Dialect, nil
+	}
+	for _, d := range Dialects {
+		if strings.EqualFold(d.Name(), name) {
+			return d, nil
+		}
+	}
+	names := make([]string, len(Dialects))
+	for i, d := range Dialects {
+		names[i] = d.Name()
+	}
+	return nil, fmt.Errorf("unknown version dialect %q, expected one of %s", name, strings.Join(names, ", "))
+}
+
+type semverDialect struct{}
+
+type semverConstraint struct {
+	raw         string
+	constraints *semver.Constraints
+}
+
+func (semverDialect) Name() string { return "semver" }
+
+func (semverDialect) ParseVersion(version string) (Version, error) {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (semverDialect) ParseConstraint(constraint string) (Constraint, error) {
+	constraints, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return nil, err
+	}
+	return semverConstraint{raw: constraint, constraints: constraints}, nil
+}
+
+func (semverDialect) Compare(a, b Version) int {
+	return a.(*semver.Version).Compare(b.(*semver.Version))
+}
+
+func (c semverConstraint) Check(v Version) bool {
+	sv, ok := v.(*semver.Version)
+	return ok && c.constraints.Check(sv)
+}
+
+func (c semverConstraint) String() string { return c.raw }
+
+type mavenDialect struct{}
+
+type mavenConstraint struct {
+	constraint *MavenConstraint
+}
+
+func (mavenDialect) Name() string { return "maven" }
+
+func (mavenDialect) ParseVersion(version string) (Version, error) {
+	return ParseMavenVersion(version), nil
+}
+
+func (mavenDialect) ParseConstraint(constraint string) (Constraint, error) {
+	c, err := ParseMavenConstraint(constraint)
+	if err != nil {
+		return nil, err
+	}
+	return mavenConstraint{constraint: c}, nil
+}
+
+func (mavenDialect) Compare(a, b Version) int {
+	return a.(*MavenVersion).Compare(b.(*MavenVersion))
+}
+
+func (c mavenConstraint) Check(v Version) bool {
+	mv, ok := v.(*MavenVersion)
+	return ok && c.constraint.Check(mv)
+}
+
+func (c mavenConstraint) String() string { return c.constraint.String() }
+
+type pep440Dialect struct{}
+
+type pep440Constraint struct {
+	specifiers *PEP440Specifiers
+}
+
+func (pep440Dialect) Name() string { return "pep440" }
+
+func (pep440Dialect) ParseVersion(version string) (Version, error) {
+	v, err := ParsePEP440Version(version)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (pep440Dialect) ParseConstraint(constraint string) (Constraint, error) {
+	s, err := ParsePEP440Specifiers(constraint)
+	if err != nil {
+		return nil, err
+	}
+	return pep440Constraint{specifiers: s}, nil
+}
+
+func (pep440Dialect) Compare(a, b Version) int {
+	return a.(*PEP440Version).Compare(b.(*PEP440Version))
+}
+
+func (c pep440Constraint) Check(v Version) bool {
+	pv, ok := v.(*PEP440Version)
+	return ok && c.specifiers.Check(pv)
+}
+
+func (c pep440Constraint) String() string { return c.specifiers.String() }
@@ -0,0 +1,126 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// ClosureDiff is the difference between the dependencies of two package versions, each resolved to the latest version
+// of every package with GetLatestTransitiveDependenciesNode. It is usually used to compare two releases of the same
+// package.
+type ClosureDiff struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Added lists the packages that only the dependencies of To have
+	Added []PackageVersion `json:"added"`
+	// Removed lists the packages that only the dependencies of From have
+	Removed []PackageVersion `json:"removed"`
+	// Changed lists the packages that both have, but resolved to different versions
+	Changed []VersionChange `json:"changed"`
+}
+
+// PackageVersion is the version a package was resolved to.
+type PackageVersion struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+}
+
+// VersionChange is a package that was resolved to different versions. Upgrade is true when To is a higher version
+// than From.
+type VersionChange struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Upgrade   bool   `json:"upgrade"`
+}
+
+// String returns the differences one per line: + for the added packages, - for the removed ones, and ~ for the ones
+// whose version changed.
+func (diff ClosureDiff) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Dependencies of %s compared to %s: %d added, %d removed, %d changed", diff.To, diff.From,
+		len(diff.Added), len(diff.Removed), len(diff.Changed))
+	for _, pkg := range diff.Added {
+		fmt.Fprintf(&b, "\n+ %s %s", pkg.Name, pkg.Version)
+	}
+	for _, pkg := range diff.Removed {
+		fmt.Fprintf(&b, "\n- %s %s", pkg.Name, pkg.Version)
+	}
+	for _, change := range diff.Changed {
+		direction := "downgrade"
+		if change.Upgrade {
+			direction = "upgrade"
+		}
+		fmt.Fprintf(&b, "\n~ %s %s -> %s (%s)", change.Name, change.From, change.To, direction)
+	}
+	return b.String()
+}
+
+// DiffClosures compares the dependencies of the specified nodes, resolved to the latest version of every package. The
+// nodes themselves are left out of the comparison, and the differences are sorted by ecosystem and name. If interested
+// in the dependencies within a specific timeframe, filter the graph first.
+func DiffClosures(g graph.Directed, nodeMap map[int64]NodeInfo, index *NodeIndex, from, to NodeKey) *ClosureDiff {
+	diff := &ClosureDiff{
+		From:    from.String(),
+		To:      to.String(),
+		Added:   make([]PackageVersion, 0),
+		Removed: make([]PackageVersion, 0),
+		Changed: make([]VersionChange, 0),
+	}
+	before := resolvedPackages(g, nodeMap, index, from)
+	after := resolvedPackages(g, nodeMap, index, to)
+
+	for pkg, node := range after {
+		previous, ok := before[pkg]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, PackageVersion{Ecosystem: node.Ecosystem, Name: node.Name, Version: node.Version})
+		case previous.Version != node.Version:
+			diff.Changed = append(diff.Changed, VersionChange{
+				Ecosystem: node.Ecosystem,
+				Name:      node.Name,
+				From:      previous.Version,
+				To:        node.Version,
+				Upgrade:   isNewerVersion(index, node, previous),
+			})
+		}
+	}
+	for pkg, node := range before {
+		if _, ok := after[pkg]; !ok {
+			diff.Removed = append(diff.Removed, PackageVersion{Ecosystem: node.Ecosystem, Name: node.Name, Version: node.Version})
+		}
+	}
+
+	sortPackageVersions(diff.Added)
+	sortPackageVersions(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		a, b := diff.Changed[i], diff.Changed[j]
+		return a.Ecosystem < b.Ecosystem || a.Ecosystem == b.Ecosystem && a.Name < b.Name
+	})
+	return diff
+}
+
+// resolvedPackages returns the latest version of every package the specified node depends on, without the node itself.
+func resolvedPackages(g graph.Directed, nodeMap map[int64]NodeInfo, index *NodeIndex, key NodeKey) map[packageKey]NodeInfo {
+	resolved := *GetLatestTransitiveDependenciesNode(g, nodeMap, index, key)
+	result := make(map[packageKey]NodeInfo, len(resolved))
+	for i, node := range resolved {
+		if i == 0 {
+			continue // The result starts with the node itself
+		}
+		result[node.Key().packageKey()] = node
+	}
+	return result
+}
+
+func sortPackageVersions(packages []PackageVersion) {
+	sort.Slice(packages, func(i, j int) bool {
+		a, b := packages[i], packages[j]
+		return a.Ecosystem < b.Ecosystem || a.Ecosystem == b.Ecosystem && a.Name < b.Name
+	})
+}
@@ -0,0 +1,65 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDiffClosures(t *testing.T) {
+	packagesInfo := []PackageInfo{
+		{Name: "app", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2021-01-01T00:00:00Z", Dependencies: map[string]string{"lib": "1.0.0", "old": "1.0.0", "shared": "1.0.0", "dep": "2.0.0"}},
+			"2.0.0": {Timestamp: "2022-01-01T00:00:00Z", Dependencies: map[string]string{"lib": ">=1.0.0", "new": "1.0.0", "shared": "1.0.0", "dep": "1.0.0"}},
+		}},
+		{Name: "lib", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2020-01-01T00:00:00Z", Dependencies: map[string]string{}},
+			"2.0.0": {Timestamp: "2021-06-01T00:00:00Z", Dependencies: map[string]string{}},
+		}},
+		{Name: "dep", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2020-01-01T00:00:00Z", Dependencies: map[string]string{}},
+			"2.0.0": {Timestamp: "2020-06-01T00:00:00Z", Dependencies: map[string]string{}},
+		}},
+		{Name: "old", Versions: map[string]VersionInfo{"1.0.0": {Timestamp: "2020-01-01T00:00:00Z", Dependencies: map[string]string{}}}},
+		{Name: "new", Versions: map[string]VersionInfo{"1.0.0": {Timestamp: "2020-01-01T00:00:00Z", Dependencies: map[string]string{}}}},
+		{Name: "shared", Versions: map[string]VersionInfo{"1.0.0": {Timestamp: "2020-01-01T00:00:00Z", Dependencies: map[string]string{}}}},
+	}
+	graph := NewDirectedGraph()
+	index, nodeMap := CreateMaps(&packagesInfo, graph)
+	CreateEdges(context.Background(), graph, &packagesInfo, index, nodeMap, SemverDialect)
+	pg := NewPackageGraph(graph, index, nodeMap)
+
+	diff, err := pg.DiffClosures(testKey("app-1.0.0"), testKey("app-2.0.0"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := &ClosureDiff{
+		From:    "app@1.0.0",
+		To:      "app@2.0.0",
+		Added:   []PackageVersion{{Name: "new", Version: "1.0.0"}},
+		Removed: []PackageVersion{{Name: "old", Version: "1.0.0"}},
+		Changed: []VersionChange{
+			{Name: "dep", From: "2.0.0", To: "1.0.0", Upgrade: false},
+			{Name: "lib", From: "1.0.0", To: "2.0.0", Upgrade: true},
+		},
+	}
+	if !reflect.DeepEqual(diff, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, diff)
+	}
+	expectedText := `Dependencies of app@2.0.0 compared to app@1.0.0: 1 added, 1 removed, 2 changed
++ new 1.0.0
+- old 1.0.0
+~ dep 2.0.0 -> 1.0.0 (downgrade)
+~ lib 1.0.0 -> 2.0.0 (upgrade)`
+	if diff.String() != expectedText {
+		t.Errorf("Expected the text\n%s\ngot\n%s", expectedText, diff)
+	}
+
+	if diff, _ := pg.DiffClosures(testKey("app-1.0.0"), testKey("app-1.0.0")); len(diff.Added)+len(diff.Removed)+len(diff.Changed) != 0 {
+		t.Errorf("Expected no differences between a version and itself, got %+v", diff)
+	}
+	if _, err := pg.DiffClosures(testKey("app-1.0.0"), testKey("app-3.0.0")); !errors.Is(err, ErrPackageNotFound) {
+		t.Errorf("Expected ErrPackageNotFound, got %v", err)
+	}
+}
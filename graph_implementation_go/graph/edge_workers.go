@@ -0,0 +1,238 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CreateEdges splits its work in two. The workers find the edges of every package concurrently, using the parsed
+// versions and specifications shared in an edgePlanner, and CreateEdges adds the edges to the graph in the order of the
+// input. The workers only read the maps and never touch the graph, so the result does not depend on the scheduling.
+
+// cachedVersion is a version of a package, parsed once for all the constraints it is checked against.
+type cachedVersion struct {
+	raw string
+	// parsed is nil when the version could not be parsed
+	parsed Version
+}
+
+// parseVersions parses the versions in the index of every package of the list, using the given number of goroutines.
+func parseVersions(inputList *[]PackageInfo, index *NodeIndex, ecosystem string, dialect Dialect, workers int) map[packageKey][]cachedVersion {
+	packages := make([]packageKey, 0, len(*inputList))
+	for _, packageInfo := range *inputList {
+		packages = append(packages, packageKey{ecosystem: ecosystem, name: packageInfo.Name})
+	}
+	parsed := make([][]cachedVersion, len(packages))
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < len(packages); i += workers {
+				versions := index.Versions(packages[i].ecosystem, packages[i].name)
+				parsed[i] = make([]cachedVersion, len(versions))
+				for j, raw := range versions {
+					v, err := dialect.ParseVersion(raw)
+					if err != nil {
+						v = nil
+					}
+					parsed[i][j] = cachedVersion{raw: raw, parsed: v}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	result := make(map[packageKey][]cachedVersion, len(packages))
+	for i, key := range packages {
+		result[key] = parsed[i]
+	}
+	return result
+}
+
+// specCache parses every dependency specification once, however many packages use it.
+type specCache struct {
+	dialect Dialect
+	specs   sync.Map
+}
+
+type cachedSpec struct {
+	spec DependencySpec
+	err  error
+}
+
+// parse returns the parsed specification of the dependency on the package name. The specifications are cached
+// without the name of the dependency, which is filled in afterwards, so all the dependencies can share them.
+func (c *specCache) parse(name, raw string) (DependencySpec, error) {
+	cached, ok := c.specs.Load(raw)
+	if !ok {
+		spec, err := parseDependencySpec(c.dialect, "", raw)
+		cached, _ = c.specs.LoadOrStore(raw, cachedSpec{spec: spec, err: err})
+	}
+	spec, err := cached.(cachedSpec).spec, cached.(cachedSpec).err
+	if spec.Name == "" {
+		spec.Name = name
+	}
+	return spec, err
+}
+
+// plannedEdge is an edge found by a worker. External dependencies do not have a node yet, so their edges only keep the
+// name of the dependency until CreateEdges creates the node.
+type plannedEdge struct {
+	from, to int64
+	external string
+	edge     DependencyEdge
+}
+
+// plannedIssue is an issue found by a worker, to be recorded in the build report.
+type plannedIssue struct {
+	issue, stringId, raw string
+}
+
+// packageEdges holds everything a worker found in a package.
+type packageEdges struct {
+	index        int
+	name         string
+	versions     int
+	dependencies int
+	edges        []plannedEdge
+	issues       []plannedIssue
+}
+
+// edgePlanner holds what the workers share. All of it is only read once the workers are started.
+type edgePlanner struct {
+	ecosystem string
+	dialect   Dialect
+	index     *NodeIndex
+	versions  map[packageKey][]cachedVersion
+	distTags  map[packageKey]map[string]string
+	specs     *specCache
+}
+
+func newEdgePlanner(inputList *[]PackageInfo, index *NodeIndex, ecosystem string, dialect Dialect, workers int) *edgePlanner {
+	distTags := make(map[packageKey]map[string]string)
+	for _, packageInfo := range *inputList {
+		if len(packageInfo.DistTags) > 0 {
+			distTags[packageKey{ecosystem: ecosystem, name: packageInfo.Name}] = packageInfo.DistTags
+		}
+	}
+	return &edgePlanner{
+		ecosystem: ecosystem,
+		dialect:   dialect,
+		index:     index,
+		versions:  parseVersions(inputList, index, ecosystem, dialect, workers),
+		distTags:  distTags,
+		specs:     &specCache{dialect: dialect},
+	}
+}
+
+// plan finds the edges of all the versions of a package. The versions and the dependencies are visited in sorted
+// order, so the edges of a package are always planned in the same order.
+func (p *edgePlanner) plan(index int, packageInfo PackageInfo) packageEdges {
+	result := packageEdges{index: index, name: packageInfo.Name, versions: len(packageInfo.Versions)}
+
+	for _, v := range p.versions[packageKey{ecosystem: p.ecosystem, name: packageInfo.Name}] {
+		if v.parsed == nil {
+			result.issues = append(result.issues, plannedIssue{IssueUnparseableVersion, fmt.Sprintf("%s-%s", packageInfo.Name, v.raw), v.raw})
+		}
+	}
+
+	versions := make([]string, 0, len(packageInfo.Versions))
+	for version := range packageInfo.Versions {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+
+	for _, version := range versions {
+		dependencyInfo := packageInfo.Versions[version]
+		packageStringId := fmt.Sprintf("%s-%s", packageInfo.Name, version)
+		packageGoId, _ := p.index.Lookup(NodeKey{Ecosystem: p.ecosystem, Name: packageInfo.Name, Version: version})
+		if _, err := time.Parse(time.RFC3339, dependencyInfo.Timestamp); err != nil {
+			result.issues = append(result.issues, plannedIssue{IssueUnparseableTimestamp, packageStringId, dependencyInfo.Timestamp})
+		}
+
+		// A package can depend on a version in several ways, in which case its edge gets all the kinds. The edges are
+		// indexed by their target to merge the kinds.
+		seen := make(map[int64]int)
+		seenExternal := make(map[string]int)
+		for _, kind := range DependencyKinds {
+			dependencies := dependencyInfo.DependenciesOfKind(kind)
+			names := make([]string, 0, len(dependencies))
+			for name := range dependencies {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, dependencyName := range names {
+				dependencyVersion := dependencies[dependencyName]
+				result.dependencies++
+				spec, err := p.specs.parse(dependencyName, dependencyVersion)
+				if err != nil {
+					// A lot of packages don't respect semver. This ensures that we don't crash when we encounter them.
+					result.issues = append(result.issues, plannedIssue{IssueUnparseableConstraint, packageStringId, dependencyVersion})
+					continue
+				}
+
+				edge := DependencyEdge{
+					Constraint:           dependencyVersion,
+					TranslatedConstraint: dependencyVersion,
+					Kind:                 kind,
+					Source:               spec.Source,
+				}
+				if spec.Source != RegistrySource {
+					key := fmt.Sprintf("%s-%s", dependencyName, dependencyVersion)
+					if i, ok := seenExternal[key]; ok {
+						result.edges[i].edge.Kind |= kind
+						continue
+					}
+					seenExternal[key] = len(result.edges)
+					result.edges = append(result.edges, plannedEdge{from: packageGoId, external: dependencyName, edge: edge})
+					continue
+				}
+
+				dependencyGoIds, translatedConstraint := p.resolve(spec)
+				if len(dependencyGoIds) == 0 {
+					result.issues = append(result.issues, plannedIssue{IssueUnresolvedDependency, packageStringId, fmt.Sprintf("%s@%s", dependencyName, dependencyVersion)})
+				}
+				edge.TranslatedConstraint = translatedConstraint
+				for _, dependencyGoId := range dependencyGoIds {
+					// Ensure that we do not create edges to self because some packages do that...
+					if dependencyGoId == packageGoId {
+						continue
+					}
+					if i, ok := seen[dependencyGoId]; ok {
+						result.edges[i].edge.Kind |= kind
+						continue
+					}
+					seen[dependencyGoId] = len(result.edges)
+					result.edges = append(result.edges, plannedEdge{from: packageGoId, to: dependencyGoId, edge: edge})
+				}
+			}
+		}
+	}
+	return result
+}
+
+// resolve returns the ids of the versions that a registry specification selects, and the constraint that was checked.
+func (p *edgePlanner) resolve(spec DependencySpec) ([]int64, string) {
+	if spec.Tag != "" {
+		// Dist-tags can only be resolved when the input has them
+		tagged, ok := p.distTags[packageKey{ecosystem: p.ecosystem, name: spec.Name}][spec.Tag]
+		if dependencyGoId, exists := p.index.Lookup(NodeKey{Ecosystem: p.ecosystem, Name: spec.Name, Version: tagged}); ok && exists {
+			return []int64{dependencyGoId}, tagged
+		}
+		return nil, spec.Tag
+	}
+
+	var dependencyGoIds []int64
+	for _, v := range p.versions[packageKey{ecosystem: p.ecosystem, name: spec.Name}] {
+		if v.parsed != nil && spec.Constraint.Check(v.parsed) {
+			dependencyGoId, _ := p.index.Lookup(NodeKey{Ecosystem: p.ecosystem, Name: spec.Name, Version: v.raw})
+			dependencyGoIds = append(dependencyGoIds, dependencyGoId)
+		}
+	}
+	return dependencyGoIds, spec.Constraint.String()
+}
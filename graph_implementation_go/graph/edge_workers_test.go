@@ -0,0 +1,94 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// createWorkersTestInput creates packages that depend on each other through ranges, dist-tags and git repositories.
+func createWorkersTestInput() []PackageInfo {
+	var packagesInfo []PackageInfo
+	for p := 0; p < 40; p++ {
+		packageInfo := PackageInfo{
+			Name:     fmt.Sprintf("p%d", p),
+			Versions: make(map[string]VersionInfo),
+			DistTags: map[string]string{"latest": "1.2.0"},
+		}
+		for v := 0; v < 6; v++ {
+			dependencies := map[string]string{
+				fmt.Sprintf("p%d", (p+1)%40): fmt.Sprintf("^1.%d.0", v%3),
+				fmt.Sprintf("p%d", (p+7)%40): "latest",
+				fmt.Sprintf("g%d", p%5):      fmt.Sprintf("github:user/g%d", p%5),
+				fmt.Sprintf("p%d", (p+3)%40): "not a range!",
+			}
+			packageInfo.Versions[fmt.Sprintf("1.%d.%d", v/2, v%2)] = VersionInfo{
+				Timestamp:       "2021-04-22T20:15:37Z",
+				Dependencies:    dependencies,
+				DevDependencies: map[string]string{fmt.Sprintf("p%d", (p+1)%40): "*"},
+			}
+		}
+		packagesInfo = append(packagesInfo, packageInfo)
+	}
+	return packagesInfo
+}
+
+func TestCreateEdgesIsDeterministic(t *testing.T) {
+	type builtEdge struct {
+		From, To string
+		Edge     string
+	}
+	build := func(workers int) ([]builtEdge, map[int64]NodeInfo, *BuildReport) {
+		packagesInfo := createWorkersTestInput()
+		graph := NewDirectedGraph()
+		index, nodeMap := CreateMaps(&packagesInfo, graph)
+		report := newBuildReport()
+		createEdges(context.Background(), graph, &packagesInfo, index, nodeMap, "", NpmDialect, workers, report, nil)
+		report.finish()
+
+		var edges []builtEdge
+		for it := graph.Edges(); it.Next(); {
+			e := it.Edge().(DependencyEdge)
+			from, to := nodeMap[e.F.ID()], nodeMap[e.T.ID()]
+			edges = append(edges, builtEdge{from.Name + "-" + from.Version, to.Name + "-" + to.Version, e.String()})
+		}
+		sort.Slice(edges, func(i, j int) bool {
+			return edges[i].From < edges[j].From || edges[i].From == edges[j].From && edges[i].To < edges[j].To
+		})
+		externals := make(map[int64]NodeInfo)
+		for id, info := range nodeMap {
+			if info.External {
+				externals[id] = info
+			}
+		}
+		return edges, externals, report
+	}
+
+	edges, externals, report := build(1)
+	// Every version depends on all 6 versions of the next package (some as runtime and the rest as dev dependencies),
+	// the latest version of another one and a git repository. The unparseable range is left out.
+	if len(edges) != 240*8 {
+		t.Errorf("Expected %d edges, got %d", 240*8, len(edges))
+	}
+	if len(externals) != 5 {
+		t.Errorf("Expected 5 external nodes, got %d", len(externals))
+	}
+	if e := report.Ecosystems["npm"]; e.UnparseableConstraints != 240 || e.Dependencies != 240*5 {
+		t.Errorf("Expected 240 unparseable constraints out of 1200 dependencies, got %d out of %d", e.UnparseableConstraints, e.Dependencies)
+	}
+
+	for _, workers := range []int{2, 8} {
+		otherEdges, otherExternals, otherReport := build(workers)
+		if !reflect.DeepEqual(edges, otherEdges) {
+			t.Errorf("Expected the same edges with %d workers", workers)
+		}
+		if !reflect.DeepEqual(externals, otherExternals) {
+			t.Errorf("Expected the same external nodes with %d workers", workers)
+		}
+		if !reflect.DeepEqual(report, otherReport) {
+			t.Errorf("Expected the same report with %d workers", workers)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+// DependencyKind describes why a package version depends on another one. Every kind is a bit, so a DependencyKind can
+// also hold several kinds: the Kind of an edge holds all the kinds in which a version depends on the other one.
+type DependencyKind uint8
+
+const (
+	Runtime DependencyKind = 1 << iota
+	Dev
+	Optional
+	Peer
+	Test
+)
+
+// DependencyKinds lists all the kinds in the order in which CreateEdges processes them. When a package version
+// depends on the same version of a package in several ways, the edge keeps the constraint of the first of its kinds
+// in this list.
+var DependencyKinds = []DependencyKind{Runtime, Dev, Optional, Peer, Test}
+
+var dependencyKindNames = map[DependencyKind]string{
+	Runtime:  "runtime",
+	Dev:      "dev",
+	Optional: "optional",
+	Peer:     "peer",
+	Test:     "test",
+}
+
+// String returns the name of the kind, or the names of the kinds joined by + when there are several.
+func (k DependencyKind) String() string {
+	if name, ok := dependencyKindNames[k]; ok {
+		return name
+	}
+	var names []string
+	rest := k
+	for _, kind := range DependencyKinds {
+		if k.Has(kind) {
+			names = append(names, dependencyKindNames[kind])
+			rest &^= kind
+		}
+	}
+	if len(names) == 0 || rest != 0 {
+		return fmt.Sprintf("DependencyKind(%d)", k)
+	}
+	return strings.Join(names, "+")
+}
+
+// Has returns whether k holds any of the given kinds.
+func (k DependencyKind) Has(kinds DependencyKind) bool {
+	return k&kinds != 0
+}
+
+// ParseDependencyKind returns the kind with the given name (runtime, dev, optional, peer or test).
+func ParseDependencyKind(name string) (DependencyKind, error) {
+	for _, k := range DependencyKinds {
+		if strings.EqualFold(dependencyKindNames[k], name) {
+			return k, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown dependency kind %q", name)
+}
+
+// DependencySource describes where a dependency comes from. Only registry dependencies point to package versions of
+// the input. The other ones point to external nodes, whose version is the specification of the dependency.
+type DependencySource uint8
+
+const (
+	RegistrySource DependencySource = iota
+	GitSource
+	URLSource
+	LocalSource
+)
+
+var dependencySourceNames = [...]string{
+	RegistrySource: "registry",
+	GitSource:      "git",
+	URLSource:      "url",
+	LocalSource:    "local",
+}
+
+func (s DependencySource) String() string {
+	if int(s) < len(dependencySourceNames) {
+		return dependencySourceNames[s]
+	}
+	return fmt.Sprintf("DependencySource(%d)", s)
+}
+
+// DependencyEdge is an edge from a package version to a version of one of its dependencies. Besides the endpoints, it
+// keeps the constraint that the dependency version satisfies, so users can see why the edge exists.
+type DependencyEdge struct {
+	F, T graph.Node
+
+	// Constraint is the constraint exactly as it was found in the input
+	Constraint string
+	// TranslatedConstraint is the constraint that was checked against the versions. It only differs from Constraint
+	// when the constraint had to be translated, as it happens for Maven
+	TranslatedConstraint string
+	// Kind holds all the kinds in which the from-version depends on the to-version
+	Kind   DependencyKind
+	Source DependencySource
+}
+
+// From returns the from-node of the edge.
+func (e DependencyEdge) From() graph.Node { return e.F }
+
+// To returns the to-node of the edge.
+func (e DependencyEdge) To() graph.Node { return e.T }
+
+// ReversedEdge returns a new DependencyEdge with the F and T fields swapped.
+func (e DependencyEdge) ReversedEdge() graph.Edge {
+	e.F, e.T = e.T, e.F
+	return e
+}
+
+// Attributes labels the edge with its constraint and kind when the graph is written to a dot file.
+func (e DependencyEdge) Attributes() []encoding.Attribute {
+	return []encoding.Attribute{{Key: "label", Value: e.String()}}
+}
+
+func (e DependencyEdge) String() string {
+	if e.Source != RegistrySource {
+		return fmt.Sprintf("%s (%s, %s)", e.Constraint, e.Kind, e.Source)
+	}
+	return fmt.Sprintf("%s (%s)", e.Constraint, e.Kind)
+}
+
+// DependencyEdgeBetween returns the dependency edge from the node with id from to the node with id to. The bool is
+// false when there is no such edge, or when the edge does not hold any dependency information.
+func DependencyEdgeBetween(g graph.Directed, from, to int64) (DependencyEdge, bool) {
+	e, ok := g.Edge(from, to).(DependencyEdge)
+	return e, ok
+}
+
+// FilterKindView returns a view of g that only contains the edges of any of the given kinds. Edges that do not hold
+// any dependency information are treated as runtime dependencies.
+func FilterKindView(g graph.Directed, kinds ...DependencyKind) *GraphView {
+	var keep DependencyKind
+	for _, k := range kinds {
+		keep |= k
+	}
+	return NewEdgeView(g, func(e graph.Edge) bool {
+		return edgeKind(e).Has(keep)
+	})
+}
+
+// edgeKind returns the kinds of the edge, which are runtime for edges that do not hold any dependency information.
+func edgeKind(e graph.Edge) DependencyKind {
+	if de, ok := e.(DependencyEdge); ok {
+		return de.Kind
+	}
+	return Runtime
+}
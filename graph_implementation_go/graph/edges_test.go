@@ -0,0 +1,93 @@
+package graph
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDependencyEdges(t *testing.T) {
+	packagesInfo := []PackageInfo{
+		{
+			Name: "B",
+			Versions: map[string]VersionInfo{
+				"1.0.0": {
+					Timestamp: "2021-04-22T20:15:37Z",
+					Dependencies: map[string]string{
+						"A": ">=1.0.0",
+					},
+					DevDependencies: map[string]string{
+						"A": "1.0.0",
+						"C": "1.0.0",
+					},
+				},
+			},
+		},
+		{
+			Name: "C",
+			Versions: map[string]VersionInfo{
+				"1.0.0": {
+					Timestamp:    "2022-04-22T20:13:34Z",
+					Dependencies: map[string]string{},
+				},
+			},
+		},
+		{
+			Name: "A",
+			Versions: map[string]VersionInfo{
+				"1.0.0": {
+					Timestamp:    "2021-04-01T20:15:37Z",
+					Dependencies: map[string]string{},
+				},
+			},
+		},
+	}
+	graph := NewDirectedGraph()
+	index, nodeMap := CreateMaps(&packagesInfo, graph)
+	CreateEdges(context.Background(), graph, &packagesInfo, index, nodeMap, SemverDialect)
+
+	bID := nodeMap[lookupTestNode(index, NodeKey{Name: "B", Version: "1.0.0"})].id
+	aID := nodeMap[lookupTestNode(index, NodeKey{Name: "A", Version: "1.0.0"})].id
+	cID := nodeMap[lookupTestNode(index, NodeKey{Name: "C", Version: "1.0.0"})].id
+
+	t.Run("Keeps the constraint and the kind on the edges", func(t *testing.T) {
+		e, ok := DependencyEdgeBetween(graph, bID, cID)
+		if !ok {
+			t.Fatal("Expected an edge from B-1.0.0 to C-1.0.0")
+		}
+		if e.Constraint != "1.0.0" || e.Kind != Dev {
+			t.Errorf("Expected constraint 1.0.0 of kind dev, got %v", e)
+		}
+	})
+
+	t.Run("Keeps all the kinds when a dependency has several kinds", func(t *testing.T) {
+		e, ok := DependencyEdgeBetween(graph, bID, aID)
+		if !ok {
+			t.Fatal("Expected an edge from B-1.0.0 to A-1.0.0")
+		}
+		if e.Constraint != ">=1.0.0" || e.Kind != Runtime|Dev || e.Kind.String() != "runtime+dev" {
+			t.Errorf("Expected constraint >=1.0.0 of kinds runtime+dev, got %v", e)
+		}
+	})
+
+	t.Run("Filters the edges on their kind", func(t *testing.T) {
+		view := FilterKindView(graph, Runtime)
+		if view.From(bID).Len() != 1 || !view.HasEdgeFromTo(bID, aID) || view.HasEdgeFromTo(bID, cID) {
+			t.Errorf("Expected only the runtime edge to A-1.0.0, got %d edges", view.From(bID).Len())
+		}
+		view = FilterKindView(graph, Dev)
+		if view.From(bID).Len() != 2 {
+			t.Errorf("Expected the dev edges to A-1.0.0 and C-1.0.0, got %d edges", view.From(bID).Len())
+		}
+	})
+}
+
+func TestParseDependencyKind(t *testing.T) {
+	for _, kind := range DependencyKinds {
+		if parsed, err := ParseDependencyKind(kind.String()); err != nil || parsed != kind {
+			t.Errorf("Expected %v, got %v (%v)", kind, parsed, err)
+		}
+	}
+	if _, err := ParseDependencyKind("build"); err == nil {
+		t.Error("Expected an error for an unknown kind")
+	}
+}
@@ -0,0 +1,84 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrPackageNotFound is returned when a package version is not in the graph, or was filtered out of it.
+var ErrPackageNotFound = errors.New("package not found")
+
+// ErrDialectConflict is returned when the inputs of the same ecosystem are read with different dialects.
+var ErrDialectConflict = errors.New("conflicting dialects")
+
+// TimestampParseError is returned when the timestamp of a package version is not a valid RFC 3339 date.
+type TimestampParseError struct {
+	Key       NodeKey
+	Timestamp string
+	Err       error
+}
+
+func (e *TimestampParseError) Error() string {
+	return fmt.Sprintf("invalid timestamp %q of %s: %v", e.Timestamp, e.Key, e.Err)
+}
+
+func (e *TimestampParseError) Unwrap() error {
+	return e.Err
+}
+
+// parseTimestamp parses the timestamp of the node, and returns a *TimestampParseError when it is not valid.
+func parseTimestamp(nodeInfo NodeInfo) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, nodeInfo.Timestamp)
+	if err != nil {
+		return time.Time{}, &TimestampParseError{Key: nodeInfo.Key(), Timestamp: nodeInfo.Timestamp, Err: err}
+	}
+	return t, nil
+}
+
+// RecordError is returned by PackageReader and NDJSONReader when a single package of the input cannot be decoded. The
+// rest of the input is still valid, so the reader can go on with the next package.
+type RecordError struct {
+	// Record is the position of the package in the input, such as "line 3" or "package 2"
+	Record string
+	Err    error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("%v: %s: %v", ErrInputFormat, e.Record, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes errors.Is report a RecordError as an ErrInputFormat.
+func (e *RecordError) Is(target error) bool {
+	return target == ErrInputFormat
+}
+
+// ErrorMode selects what CreateGraph does with the records of the input that are not valid.
+type ErrorMode uint8
+
+const (
+	// BestEffort leaves out the packages that cannot be decoded and counts them in the build report. The versions
+	// with timestamps that cannot be parsed are kept and counted in the report as well, but the filters on the
+	// release dates leave them out.
+	BestEffort ErrorMode = iota
+	// Strict stops at the first package that cannot be decoded or whose timestamp cannot be parsed.
+	Strict
+)
+
+func (m ErrorMode) String() string {
+	if m == Strict {
+		return "strict"
+	}
+	return "best-effort"
+}
+
+// BuildOptions holds the options of CreateGraph. The zero value builds the graph in best-effort mode, without reporting
+// its progress.
+type BuildOptions struct {
+	Mode     ErrorMode
+	Progress ProgressReporter
+}
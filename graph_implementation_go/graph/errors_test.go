@@ -0,0 +1,109 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateGraphErrorModes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.ndjson")
+	content := `{"name": "A", "versions": {"1.0.0": {"timestamp": "2021-04-22T20:15:37Z", "dependencies": {"B": "^1.0.0"}}}}
+{"name": "B", "versions": {"1.0.0": {"timestamp": "yesterday", "dependencies": {}}}}
+{"name": "C", "versions": "not an object"}
+{"name": "D", "versions": {"1.0.0": {"timestamp": "2021-04-01T20:15:37Z", "dependencies": {}}}}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	input := Input{Path: path, Dialect: NpmDialect}
+
+	t.Run("Best effort leaves out malformed records", func(t *testing.T) {
+		pg, report, err := CreateGraph(context.Background(), BuildOptions{}, input)
+		if err != nil {
+			t.Fatalf("Creating the graph failed: %v", err)
+		}
+		if len(pg.Nodes()) != 3 {
+			t.Errorf("Expected A, B and D, got %v", pg.Nodes())
+		}
+		e := report.Ecosystems["npm"]
+		if e.MalformedRecords != 1 || e.UnparseableTimestamps != 1 {
+			t.Errorf("Expected a malformed record and an unparseable timestamp, got %s", report)
+		}
+	})
+
+	t.Run("Best effort filters leave out unparseable timestamps", func(t *testing.T) {
+		pg, _, err := CreateGraph(context.Background(), BuildOptions{}, input)
+		if err != nil {
+			t.Fatalf("Creating the graph failed: %v", err)
+		}
+		between, err := pg.Filter(ReleasedBetween(time.Time{}, time.Now()))
+		if err != nil {
+			t.Fatalf("Filtering the releases failed: %v", err)
+		}
+		if nodes := between.Nodes(); len(nodes) != 2 || nodes[0].Name == "B" || nodes[1].Name == "B" {
+			t.Errorf("Expected A and D, got %v", nodes)
+		}
+		latest, err := pg.Filter(LatestReleases())
+		if err != nil {
+			t.Fatalf("Filtering the latest releases failed: %v", err)
+		}
+		if _, ok := latest.LookupKey(NodeKey{Ecosystem: "npm", Name: "B", Version: "1.0.0"}); ok || len(latest.Nodes()) != 2 {
+			t.Errorf("Expected A and D, got %v", latest.Nodes())
+		}
+	})
+
+	t.Run("Strict stops at the first invalid record", func(t *testing.T) {
+		var timestampErr *TimestampParseError
+		if _, _, err := CreateGraph(context.Background(), BuildOptions{Mode: Strict}, input); !errors.As(err, &timestampErr) ||
+			timestampErr.Key != (NodeKey{Ecosystem: "npm", Name: "B", Version: "1.0.0"}) {
+			t.Errorf("Expected a *TimestampParseError for B, got %v", err)
+		}
+
+		malformed := filepath.Join(t.TempDir(), "malformed.ndjson")
+		if err := os.WriteFile(malformed, []byte(strings.Join(strings.Split(content, "\n")[2:], "\n")), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		var recordErr *RecordError
+		if _, _, err := CreateGraph(context.Background(), BuildOptions{Mode: Strict}, Input{Path: malformed, Dialect: NpmDialect}); !errors.As(err, &recordErr) ||
+			recordErr.Record != "line 1" {
+			t.Errorf("Expected a *RecordError on line 1, got %v", err)
+		}
+	})
+
+	t.Run("Conflicting dialects", func(t *testing.T) {
+		if _, _, err := CreateGraph(context.Background(), BuildOptions{}, input, Input{Path: path, Ecosystem: "npm", Dialect: SemverDialect}); !errors.Is(err, ErrDialectConflict) {
+			t.Errorf("Expected ErrDialectConflict, got %v", err)
+		}
+	})
+}
+
+func TestPackageReaderSkipsRecords(t *testing.T) {
+	pr := NewPackageReader(strings.NewReader(`{"pkgs": [{"name": "A", "versions": 1}, {"name": "B"}]}`))
+	var recordErr *RecordError
+	if _, err := pr.Read(); !errors.As(err, &recordErr) || !errors.Is(err, ErrInputFormat) || recordErr.Record != "package 0" {
+		t.Fatalf("Expected a *RecordError for package 0, got %v", err)
+	}
+	if packageInfo, err := pr.Read(); err != nil || packageInfo.Name != "B" {
+		t.Errorf("Expected to read B after the malformed package, got %v (%v)", packageInfo, err)
+	}
+}
+
+func TestFilterNoTraversalInvalidTimestamp(t *testing.T) {
+	graph, _, nodeMap := createDependentsTestGraph()
+	for id, info := range nodeMap {
+		if info.Name == "C" {
+			info.Timestamp = "yesterday"
+			nodeMap[id] = info
+		}
+	}
+	nodes := graph.Nodes().Len()
+
+	FilterNoTraversal(graph, nodeMap, time.Time{}, time.Now())
+	if graph.Nodes().Len() != nodes-1 {
+		t.Errorf("Expected only C to be removed, got %d nodes instead of %d", graph.Nodes().Len(), nodes-1)
+	}
+}
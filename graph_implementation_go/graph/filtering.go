@@ -1,31 +1,36 @@
 package graph
 
 import (
-	"github.com/Masterminds/semver"
+	"gonum.org/v1/gonum/graph"
 	"time"
 )
 
+// FilterView returns a view of g that only contains the nodes that have timestamps between beginTime and endTime.
+// Unlike FilterNoTraversal, g is not modified, so the view can be thrown away once it is no longer needed. The nodes
+// whose timestamp cannot be parsed are left out, since they were already counted in the report of the build.
+func FilterView(g graph.Directed, nodeMap map[int64]NodeInfo, beginTime, endTime time.Time) *GraphView {
+	return newSetView(g, nodesInInterval(g.Nodes(), nodeMap, beginTime, endTime))
+}
+
+// FilterLatestView returns a view of g that only contains the latest/newest release of every package. If interested
+// in finding the latest packages in a timeframe, the view should be created on top of the one returned by FilterView.
+// Unlike FilterLatestNoTraversal, g is not modified. The nodes whose timestamp cannot be parsed are left out.
+func FilterLatestView(g graph.Directed, nodeMap map[int64]NodeInfo, index *NodeIndex) *GraphView {
+	newestPackageVersion := newestPackageVersions(g.Nodes(), nodeMap, index)
+	keepIDs := make(map[int64]struct{}, len(newestPackageVersion))
+	for _, v := range newestPackageVersion {
+		keepIDs[v.id] = struct{}{}
+	}
+	return newSetView(g, keepIDs)
+}
+
 // FilterNoTraversal filters the nodes that have timestamps between beginTime and endTime. WARNING: This method is destructive,
 // meaning that after running it, the nodes and their associated edges that do not correspond to the filter WILL BE REMOVED
-// from the graph
+// from the graph. Use FilterView to keep the graph intact. The nodes whose timestamp cannot be parsed are removed too.
 func FilterNoTraversal(g *DirectedGraph, nodeMap map[int64]NodeInfo, beginTime, endTime time.Time) {
-	nodes := g.Nodes()
+	nodesInInterval := nodesInInterval(g.Nodes(), nodeMap, beginTime, endTime)
 
-	nodesInInterval := make(map[int64]struct{}, len(nodeMap))
 	removeIDs := make(map[int64]struct{}, len(nodeMap))
-
-	for nodes.Next() { // Find nodes that are in the correct time interval
-		n := nodes.Node()
-		id := n.ID()
-		publishTime, err := time.Parse(time.RFC3339, nodeMap[id].Timestamp)
-		if err != nil {
-			panic(err)
-		}
-		if InInterval(publishTime, beginTime, endTime) {
-			nodesInInterval[id] = struct{}{}
-		}
-	}
-
 	for id := range nodeMap {
 		if _, ok := nodesInInterval[id]; !ok { // If the node id was not on the list, kick it out
 			removeIDs[id] = struct{}{}
@@ -38,56 +43,86 @@ func FilterNoTraversal(g *DirectedGraph, nodeMap map[int64]NodeInfo, beginTime,
 // FilterLatestNoTraversal filters the nodes in the graph to their latest/newest releases. If interested in finding
 // the latest packages in a timeframe, FilterNoTraversal needs to be called first. WARNING: This method is destructive,
 // meaning that after running it, the nodes and their associated edges that do not correspond to the filter WILL BE REMOVED
-// from the graph
-func FilterLatestNoTraversal(g *DirectedGraph, nodeMap map[int64]NodeInfo) {
-	length := g.Nodes().Len() / 2
-	newestPackageVersion := make(map[uint32]NodeInfo, length)
+// from the graph. Use FilterLatestView to keep the graph intact. The nodes whose timestamp cannot be parsed are removed
+// too.
+func FilterLatestNoTraversal(g *DirectedGraph, nodeMap map[int64]NodeInfo, index *NodeIndex) {
+	newestPackageVersion := newestPackageVersions(g.Nodes(), nodeMap, index)
+
+	length := len(newestPackageVersion)
 	keepIDs := make(map[int64]struct{}, length)
 	removeIDs := make(map[int64]struct{}, length)
-	nodes := g.Nodes()
+
+	for _, v := range newestPackageVersion {
+		keepIDs[v.id] = struct{}{}
+	}
+
+	for id := range nodeMap {
+		if _, ok := keepIDs[id]; !ok { // If the node id was not on the list, kick it out
+			removeIDs[id] = struct{}{}
+		}
+	}
+
+	keepSelectedNodes(g, removeIDs)
+}
+
+// nodesInInterval returns the ids of the nodes that have timestamps between beginTime and endTime. External nodes do
+// not have timestamps, and the timestamps of the other nodes may not be valid in a best-effort build, so they are all
+// left out.
+func nodesInInterval(nodes graph.Nodes, nodeMap map[int64]NodeInfo, beginTime, endTime time.Time) map[int64]struct{} {
+	result := make(map[int64]struct{}, nodes.Len())
+
+	for nodes.Next() { // Find nodes that are in the correct time interval
+		id := nodes.Node().ID()
+		if nodeMap[id].External { // External nodes are never released, so they are not in any interval
+			continue
+		}
+		publishTime, err := parseTimestamp(nodeMap[id])
+		if err != nil {
+			continue
+		}
+		if InInterval(publishTime, beginTime, endTime) {
+			result[id] = struct{}{}
+		}
+	}
+
+	return result
+}
+
+// newestPackageVersions returns the latest/newest release of every package among the given nodes, keyed by the
+// ecosystem and the name of the package. The versions released at the same time are ordered with the dialects of the
+// index.
+func newestPackageVersions(nodes graph.Nodes, nodeMap map[int64]NodeInfo, index *NodeIndex) map[packageKey]NodeInfo {
+	newestPackageVersion := make(map[packageKey]NodeInfo, nodes.Len()/2)
 
 	for nodes.Next() {
 		n := nodes.Node()
 		current := nodeMap[n.ID()]
-		currentDate, err := time.Parse(time.RFC3339, nodeMap[n.ID()].Timestamp)
+		if current.External {
+			continue
+		}
+		currentDate, err := parseTimestamp(current)
 		if err != nil {
-			panic(err)
+			continue // The versions with invalid timestamps are counted in the report of the build
 		}
-		hash := hashPackageName(current.Name)
+		pkg := current.Key().packageKey()
 
-		if latest, ok := newestPackageVersion[hash]; ok {
-			latestDate, err := time.Parse(time.RFC3339, latest.Timestamp)
-			if err != nil {
-				panic(err)
-			}
+		if latest, ok := newestPackageVersion[pkg]; ok {
+			// Only the versions with valid timestamps are stored
+			latestDate, _ := parseTimestamp(latest)
 			if currentDate.After(latestDate) { // If the key exists, and current date is later than the one stored
-				newestPackageVersion[hash] = current // Set to the current package
+				newestPackageVersion[pkg] = current // Set to the current package
 			} else if currentDate.Equal(latestDate) { // If the dates are somehow equal, compare version numbers
-				currentVersion, _ := semver.NewVersion(current.Version)
-				latestVersion, _ := semver.NewVersion(latest.Version)
-
-				if currentVersion.GreaterThan(latestVersion) {
-					newestPackageVersion[hash] = current
+				if isNewerVersion(index, current, latest) {
+					newestPackageVersion[pkg] = current
 				}
 			}
 		} else { // If the key doesn't exist yet
-			newestPackageVersion[hash] = current
+			newestPackageVersion[pkg] = current
 		}
 
 	}
 
-	for _, v := range newestPackageVersion {
-		keepIDs[v.id] = struct{}{}
-	}
-
-	for id := range nodeMap {
-		if _, ok := keepIDs[id]; !ok { // If the node id was not on the list, kick it out
-			removeIDs[id] = struct{}{}
-		}
-	}
-
-	keepSelectedNodes(g, removeIDs)
-
+	return newestPackageVersion
 }
 
 func keepSelectedNodes(g *DirectedGraph, removeIDs map[int64]struct{}) {
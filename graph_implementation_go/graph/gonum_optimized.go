@@ -3,8 +3,8 @@
 // license that can be found in the third_party_licenses/Gonum-LICENSE file.
 
 // This code was modified by Andrei Purcaru in order to better suit the needs of this repository. The changes include
-// the removal of the "to" map from the graph data structure and the change of a method that was using it into a method
-// that panics instantly when called.
+// the removal of the "to" map from the graph data structure. The map can be brought back on demand with
+// EnableReverseIndex, and the To method panics when it is called before that.
 
 package graph
 
@@ -13,6 +13,7 @@ import (
 	"gonum.org/v1/gonum/graph"
 	"gonum.org/v1/gonum/graph/iterator"
 	"gonum.org/v1/gonum/graph/set/uid"
+	"sync"
 )
 
 var (
@@ -26,10 +27,14 @@ var (
 	_ graph.EdgeRemover = dg
 )
 
-// DirectedGraph implements a generalized directed graph.
+// DirectedGraph implements a generalized directed graph. The reverse index (to) is nil unless it was
+// enabled with EnableReverseIndex, since it doubles the memory used by the edges.
 type DirectedGraph struct {
 	nodes map[int64]graph.Node
 	from  map[int64]map[int64]graph.Edge
+	to    map[int64]map[int64]graph.Edge
+	// reverseIndexMu guards building the reverse index, so queries can build it concurrently
+	reverseIndexMu sync.Mutex
 
 	nodeIDs *uid.Set
 }
@@ -53,6 +58,39 @@ func (g *DirectedGraph) AddNode(n graph.Node) {
 	g.nodeIDs.Use(n.ID())
 }
 
+// EnableReverseIndex builds the reverse index of the graph, which is needed by To. The index is kept up to date by
+// all the following mutations of the graph. Calling it when the index already exists is a no-op. It is safe to call it
+// concurrently, but not concurrently with mutations of the graph.
+func (g *DirectedGraph) EnableReverseIndex() {
+	g.reverseIndexMu.Lock()
+	defer g.reverseIndexMu.Unlock()
+	if g.to != nil {
+		return
+	}
+	to := make(map[int64]map[int64]graph.Edge, len(g.nodes))
+	for fid, edges := range g.from {
+		if _, ok := g.nodes[fid]; !ok {
+			continue
+		}
+		for tid, e := range edges {
+			if _, ok := g.nodes[tid]; !ok {
+				continue
+			}
+			if tm, ok := to[tid]; ok {
+				tm[fid] = e
+			} else {
+				to[tid] = map[int64]graph.Edge{fid: e}
+			}
+		}
+	}
+	g.to = to
+}
+
+// HasReverseIndex returns whether the reverse index of the graph was enabled.
+func (g *DirectedGraph) HasReverseIndex() bool {
+	return g.to != nil
+}
+
 // Edge returns the edge from u to v if such an edge exists and nil otherwise.
 // The node v must be directly reachable from u as defined by the From method.
 func (g *DirectedGraph) Edge(uid, vid int64) graph.Edge {
@@ -162,6 +200,9 @@ func (g *DirectedGraph) RemoveEdge(fid, tid int64) {
 	}
 
 	delete(g.from[fid], tid)
+	if g.to != nil {
+		delete(g.to[tid], fid)
+	}
 }
 
 // RemoveNode removes the node with the given ID from the graph, as well as any edges attached
@@ -172,6 +213,17 @@ func (g *DirectedGraph) RemoveNode(id int64) {
 	}
 	delete(g.nodes, id)
 
+	if g.to != nil {
+		for from := range g.to[id] {
+			delete(g.from[from], id)
+		}
+		delete(g.to, id)
+		for to := range g.from[id] {
+			delete(g.to[to], id)
+		}
+		delete(g.from, id)
+	}
+
 	g.nodeIDs.Release(id)
 }
 
@@ -206,12 +258,26 @@ func (g *DirectedGraph) SetEdge(e graph.Edge) {
 	} else {
 		g.from[fid] = map[int64]graph.Edge{tid: e}
 	}
+	if g.to != nil {
+		if tm, ok := g.to[tid]; ok {
+			tm[fid] = e
+		} else {
+			g.to[tid] = map[int64]graph.Edge{fid: e}
+		}
+	}
 }
 
-// To returns all nodes in g that can reach directly to n.
+// To returns all nodes in g that can reach directly to n. It panics if the reverse index was not
+// enabled with EnableReverseIndex.
 //
 // The returned graph.Nodes is only valid until the next mutation of
 // the receiver.
 func (g *DirectedGraph) To(id int64) graph.Nodes {
-	panic("Not implemented due to optimization")
-}
\ No newline at end of file
+	if g.to == nil {
+		panic("Not implemented due to optimization, call EnableReverseIndex first")
+	}
+	if len(g.to[id]) == 0 {
+		return graph.Empty
+	}
+	return iterator.NewNodesByEdge(g.nodes, g.to[id])
+}
@@ -1,22 +1,47 @@
 package graph
 
 import (
+	"context"
 	"fmt"
-	"github.com/Masterminds/semver"
-	"github.com/mailru/easyjson"
-	"gonum.org/v1/gonum/graph/simple"
-	"log"
-	"os"
+	"runtime"
+	"sort"
+	"sync"
 )
 
+// VersionInfo holds the dependencies of a package version, mapped to their version constraints. Dependencies holds the
+// runtime dependencies. The other kinds of dependencies are optional in the input.
 type VersionInfo struct {
-	Dependencies map[string]string `json:"dependencies"`
-	Timestamp    string            `json:"timestamp"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies,omitempty"`
+	OptionalDependencies map[string]string `json:"optionalDependencies,omitempty"`
+	PeerDependencies     map[string]string `json:"peerDependencies,omitempty"`
+	TestDependencies     map[string]string `json:"testDependencies,omitempty"`
+	Timestamp            string            `json:"timestamp"`
+}
+
+// DependenciesOfKind returns the dependencies of the given kind, mapped to their version constraints.
+func (versionInfo VersionInfo) DependenciesOfKind(kind DependencyKind) map[string]string {
+	switch kind {
+	case Runtime:
+		return versionInfo.Dependencies
+	case Dev:
+		return versionInfo.DevDependencies
+	case Optional:
+		return versionInfo.OptionalDependencies
+	case Peer:
+		return versionInfo.PeerDependencies
+	case Test:
+		return versionInfo.TestDependencies
+	default:
+		return nil
+	}
 }
 
 type PackageInfo struct {
 	Versions map[string]VersionInfo `json:"versions"`
 	Name     string                 `json:"name"`
+	// DistTags maps the dist-tags of npm packages, such as latest or next, to the versions they point to
+	DistTags map[string]string `json:"dist-tags,omitempty"`
 }
 
 type Doc struct {
@@ -26,9 +51,14 @@ type Doc struct {
 // NodeInfo is a type structure for nodes. Name and Version can be removed if we find we don't use them often enough
 type NodeInfo struct {
 	Timestamp string
+	// Ecosystem is the ecosystem of the package, such as npm or pypi. It is empty for graphs created with CreateMaps
+	Ecosystem string
 	Name      string
 	Version   string
-	id        int64
+	// External is true for the nodes that stand for dependencies outside the registry, such as git repositories. Their
+	// version is the specification of the dependency and they do not have a timestamp
+	External bool
+	id       int64
 }
 
 // NewNodeInfo constructs a NodeInfo structure and automatically fills the stringID.
@@ -44,120 +74,261 @@ func (nodeInfo NodeInfo) String() string {
 	return fmt.Sprintf("Package: %v - Version: %v", nodeInfo.Name, nodeInfo.Version)
 }
 
-func CreateGraph(inputPath string, isUsingMaven bool) (*DirectedGraph, map[uint64]int64, map[int64]NodeInfo) {
-	fmt.Println("Parsing input")
-	packagesList := ParseJSON(inputPath)
-
+// CreateGraph reads the packages in the input files and creates their dependency graph. Every input has its own
+// ecosystem and dialect, and the dependencies of a package only resolve to packages of the same ecosystem, so packages
+// with the same name in different ecosystems stay apart. The report lists what had to be left out of the graph. The
+// mode of the options selects whether invalid packages are left out or stop the build, in which case the error is a
+// *RecordError or a *TimestampParseError. The progress of the build is passed to the reporter of the options, and the
+// build stops with the error of ctx when ctx is cancelled.
+func CreateGraph(ctx context.Context, options BuildOptions, inputs ...Input) (*PackageGraph, *BuildReport, error) {
 	directedGraph := NewDirectedGraph()
+	index := NewNodeIndex()
+	idToNodeInfo := make(map[int64]NodeInfo)
+
+	report := newBuildReport()
 
-	fmt.Println("Adding nodes and creating indices")
+	// Inputs of the same ecosystem are connected together, in the order of their first input
+	packagesRead := 0
+	var ecosystems []*ecosystemInput
+	byName := make(map[string]*ecosystemInput)
+	for _, input := range inputs {
+		name := input.ecosystem()
+		e, ok := byName[name]
+		if !ok {
+			e = &ecosystemInput{name: name, dialect: input.Dialect}
+			byName[name] = e
+			ecosystems = append(ecosystems, e)
+		} else if e.dialect.Name() != input.Dialect.Name() {
+			return nil, nil, fmt.Errorf("%w: the inputs of ecosystem %s use both the %s and the %s dialects",
+				ErrDialectConflict, name, e.dialect.Name(), input.Dialect.Name())
+		}
 
-	hashToNodeId, idToNodeInfo := CreateMaps(&packagesList, directedGraph)
-	hashToVersions := CreateHashedVersionMap(&packagesList)
+		var skip func(err *RecordError)
+		if options.Mode == BestEffort {
+			path := input.Path
+			skip = func(err *RecordError) {
+				report.ecosystem(name).record(IssueMalformedRecord, "", path+": "+err.Record, err.Err.Error())
+			}
+		}
+		// The nodes are added while the input is read, so the raw input is never held in memory. The decoded packages
+		// are kept until the edges are created though, since packages can depend on packages that come later in the
+		// input, so the memory needed still grows with the size of the input.
+		err := readPackages(input.Path, input.Format, func(packageInfo PackageInfo) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if options.Mode == Strict {
+				if err := checkTimestamps(name, packageInfo); err != nil {
+					return err
+				}
+			}
+			addPackageNodes(directedGraph, name, packageInfo, index, idToNodeInfo)
+			e.packages = append(e.packages, packageInfo)
+			packagesRead++
+			reportProgress(options.Progress, StageReading, packagesRead, 0)
+			return nil
+		}, skip)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
 
-	fmt.Println("Creating edges")
+	// The packages of all the ecosystems are connected in a single stage
+	connected := 0
+	for _, e := range ecosystems {
+		err := createEdges(ctx, directedGraph, &e.packages, index, idToNodeInfo, e.name, e.dialect, runtime.GOMAXPROCS(0), report, func(packages int) {
+			reportProgress(options.Progress, StageEdges, connected+packages, packagesRead)
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		connected += len(e.packages)
+		e.packages = nil // The packages of an ecosystem are not needed once it is connected
+	}
+	report.finish()
 
-	CreateEdges(directedGraph, &packagesList, hashToNodeId, hashToVersions, isUsingMaven)
+	return NewPackageGraph(directedGraph, index, idToNodeInfo), report, nil
+}
 
-	fmt.Println("Done creating edges!")
+// checkTimestamps returns a *TimestampParseError for the first version of the package, in sorted order, whose
+// timestamp cannot be parsed.
+func checkTimestamps(ecosystem string, packageInfo PackageInfo) error {
+	versions := make([]string, 0, len(packageInfo.Versions))
+	for version := range packageInfo.Versions {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	for _, version := range versions {
+		nodeInfo := NodeInfo{Ecosystem: ecosystem, Name: packageInfo.Name, Version: version, Timestamp: packageInfo.Versions[version].Timestamp}
+		if _, err := parseTimestamp(nodeInfo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	return directedGraph, hashToNodeId, idToNodeInfo
+// ecosystemInput gathers the packages of all the inputs of an ecosystem.
+//
+//easyjson:skip
+type ecosystemInput struct {
+	name     string
+	dialect  Dialect
+	packages []PackageInfo
 }
 
-func CreateMaps(packageList *[]PackageInfo, graph *DirectedGraph) (map[uint64]int64, map[int64]NodeInfo) {
-	hashToNodeId := make(map[uint64]int64, len(*packageList)*10)
+// CreateMaps adds the packages to the graph as a single ecosystem, whose name is empty.
+func CreateMaps(packageList *[]PackageInfo, graph *DirectedGraph) (*NodeIndex, map[int64]NodeInfo) {
+	index := NewNodeIndex()
 	idToNodeInfo := make(map[int64]NodeInfo, len(*packageList)*10)
 	for _, packageInfo := range *packageList {
-		for packageVersion, versionInfo := range packageInfo.Versions {
-			stringID := fmt.Sprintf("%s-%s", packageInfo.Name, packageVersion)
-			hashed := hashStringId(stringID)
-			// Delegate the work of creating a unique ID to Gonum
-			newNode := graph.NewNode()
-			newId := newNode.ID()
-			hashToNodeId[hashed] = newId
-			idToNodeInfo[newId] = *NewNodeInfo(newId, packageInfo.Name, packageVersion, versionInfo.Timestamp)
-			graph.AddNode(newNode)
+		addPackageNodes(graph, "", packageInfo, index, idToNodeInfo)
+	}
+	return index, idToNodeInfo
+}
+
+// addPackageNodes adds a node for every version of the package of the given ecosystem to the graph, the index and the
+// map of ids to NodeInfo. A version that is already in the graph, because the input lists the package twice, keeps its
+// first node.
+func addPackageNodes(graph *DirectedGraph, ecosystem string, packageInfo PackageInfo, index *NodeIndex, idToNodeInfo map[int64]NodeInfo) {
+	for packageVersion, versionInfo := range packageInfo.Versions {
+		// Delegate the work of creating a unique ID to Gonum
+		newNode := graph.NewNode()
+		newId := newNode.ID()
+		info := NewNodeInfo(newId, packageInfo.Name, packageVersion, versionInfo.Timestamp)
+		info.Ecosystem = ecosystem
+		if !index.Add(*info) {
+			continue
 		}
+		idToNodeInfo[newId] = *info
+		graph.AddNode(newNode)
+	}
+}
+
+// CreateEdges takes a graph created with CreateMaps, a list of packages and their dependencies, the index of the nodes
+// and a map of ids to NodeInfo and creates directed edges between the dependent library and its dependencies. The
+// constraints and the versions are interpreted with the given dialect, and the report names the ecosystem after it.
+// Dependencies that do not come from the registry are connected to external nodes, which are added to the graph, the
+// index and the map. Everything that is skipped because it cannot be parsed or resolved is counted in the returned
+// report.
+//
+// The packages are spread over one worker per CPU, and every version and specification is only parsed once. The edges
+// are still added in the order of the input, so the graph and the report are the same for any number of workers. When
+// ctx is cancelled, CreateEdges stops with its error and the graph is left with part of the edges.
+func CreateEdges(ctx context.Context, graph *DirectedGraph, inputList *[]PackageInfo, index *NodeIndex, idToNodeInfo map[int64]NodeInfo, dialect Dialect) (*BuildReport, error) {
+	report := newBuildReport()
+	if err := createEdges(ctx, graph, inputList, index, idToNodeInfo, "", dialect, runtime.GOMAXPROCS(0), report, nil); err != nil {
+		return nil, err
 	}
-	return hashToNodeId, idToNodeInfo
-}
-
-// CreateEdges takes a graph, a list of packages and their dependencies, a map of stringIDs to NodeInfo and
-// a map of names to versions and creates directed edges between the dependent library and its dependencies.
-func CreateEdges(graph *DirectedGraph, inputList *[]PackageInfo, hashToNodeId map[uint64]int64, hashToVersionMap map[uint32][]string, isMaven bool) {
-	packagesLength := len(*inputList)
-	edgesAmount := 0
-	channel := make(chan int, 1000)
-	go func(n int, ch chan int) {
-		for {
-			for i := range ch {
-				fmt.Printf("\u001b[1A \u001b[2K \r") // Clear the last line
-				fmt.Printf("%.2f%% done (%d / %d packages connected to their dependencies)\n", float64(i)/float64(n)*100, i, n)
+	report.finish()
+	return report, nil
+}
+
+// createEdges creates the edges of the packages of an ecosystem and records them and their issues in buildReport. The
+// number of packages connected so far is passed to progress, which may be nil.
+func createEdges(ctx context.Context, graph *DirectedGraph, inputList *[]PackageInfo, index *NodeIndex, idToNodeInfo map[int64]NodeInfo, ecosystem string, dialect Dialect, workers int, buildReport *BuildReport, progress func(packages int)) error {
+	index.SetDialect(ecosystem, dialect)
+	planner := newEdgePlanner(inputList, index, ecosystem, dialect, workers)
+
+	jobs := make(chan int, workers)
+	results := make(chan packageEdges, workers)
+	go func() {
+		defer close(jobs)
+		for id := range *inputList {
+			select {
+			case jobs <- id:
+			case <-ctx.Done(): // The workers finish the packages they have, so results is still closed
+				return
 			}
 		}
-	}(packagesLength, channel)
-	for id, packageInfo := range *inputList {
-		for version, dependencyInfo := range packageInfo.Versions {
-			for dependencyName, dependencyVersion := range dependencyInfo.Dependencies {
-				dependencySemanticVersioning := dependencyVersion
-				if isMaven {
-					dependencySemanticVersioning = ParseMultipleMavenSemanticVersions(dependencyVersion)
-				}
-				constraint, err := semver.NewConstraint(dependencySemanticVersioning)
+	}()
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				results <- planner.plan(id, (*inputList)[id])
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	reportName := ecosystem
+	if reportName == "" {
+		reportName = dialect.Name()
+	}
+	report := buildReport.ecosystem(reportName)
 
-				if err != nil {
-					// A lot of packages don't respect semver. This ensures that we don't crash when we encounter them.
+	// The workers still read the index, so the external nodes are only created once they are done
+	var externalEdges []plannedEdge
+	pending := make(map[int]packageEdges)
+	next := 0
+	for result := range results {
+		pending[result.index] = result
+		for planned, ok := pending[next]; ok; planned, ok = pending[next] {
+			delete(pending, next)
+			report.Packages++
+			report.Versions += planned.versions
+			report.Dependencies += planned.dependencies
+			for _, issue := range planned.issues {
+				report.record(issue.issue, planned.name, issue.stringId, issue.raw)
+			}
+			for _, e := range planned.edges {
+				if e.external != "" {
+					externalEdges = append(externalEdges, e)
 					continue
 				}
-				for _, v := range LookupVersions(dependencyName, hashToVersionMap) {
-					newVersion, err := semver.NewVersion(v)
-					if err != nil {
-						continue
-					}
-					if constraint.Check(newVersion) {
-						dependencyStringId := fmt.Sprintf("%s-%s", dependencyName, v)
-						dependencyGoId := LookupByStringId(dependencyStringId, hashToNodeId)
-
-						packageStringId := fmt.Sprintf("%s-%s", packageInfo.Name, version)
-						packageGoId := LookupByStringId(packageStringId, hashToNodeId)
-
-						// Ensure that we do not create edgesAmount to self because some packages do that...
-						if dependencyGoId != packageGoId {
-							packageNode := graph.Node(packageGoId)
-							dependencyNode := graph.Node(dependencyGoId)
-							graph.SetEdge(simple.Edge{F: packageNode, T: dependencyNode})
-							edgesAmount++
-						}
-
-					}
-				}
+				e.edge.F, e.edge.T = graph.Node(e.from), graph.Node(e.to)
+				graph.SetEdge(e.edge)
+				report.Edges++
+			}
+			next++
+			if progress != nil {
+				progress(next)
 			}
 		}
-		channel <- id
 	}
-	close(channel)
-	fmt.Printf("Nodes: %d, Edges: %d\n", len(hashToNodeId), edgesAmount)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for _, e := range externalEdges {
+		e.edge.F = graph.Node(e.from)
+		e.edge.T = graph.Node(externalNode(graph, index, idToNodeInfo, NodeKey{Ecosystem: ecosystem, Name: e.external, Version: e.edge.Constraint}))
+		graph.SetEdge(e.edge)
+		report.Edges++
+	}
+	return nil
 }
 
-func ParseJSON(inPath string) []PackageInfo {
-
-	f, err := os.Open(inPath)
-	if err != nil {
-		log.Fatal(err)
+// externalNode returns the id of the external node whose key has the name of the dependency and its specification as
+// version, and creates the node the first time the dependency is found.
+func externalNode(graph *DirectedGraph, index *NodeIndex, idToNodeInfo map[int64]NodeInfo, key NodeKey) int64 {
+	if id, ok := index.Lookup(key); ok {
+		return id
 	}
-	defer func(f *os.File) {
-		err := f.Close()
-		if err != nil {
-			panic(err)
-		}
-	}(f)
+	newNode := graph.NewNode()
+	newId := newNode.ID()
+	info := NewNodeInfo(newId, key.Name, key.Version, "")
+	info.Ecosystem = key.Ecosystem
+	info.External = true
+	index.Add(*info)
+	idToNodeInfo[newId] = *info
+	graph.AddNode(newNode)
+	return newId
+}
 
-	var result Doc
-	err = easyjson.UnmarshalFromReader(f, &result)
+// ParseJSON reads all the packages of the input file, which may be compressed and may have one package per line.
+// CreateGraph does not need it, since it creates the nodes while the packages are read.
+func ParseJSON(inPath string) ([]PackageInfo, error) {
+	var packages []PackageInfo
+	err := ReadPackages(inPath, AutoFormat, func(packageInfo PackageInfo) {
+		packages = append(packages, packageInfo)
+	})
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	fmt.Printf("Read %d packages\n", len(result.Pkgs))
-
-	return result.Pkgs
+	return packages, nil
 }
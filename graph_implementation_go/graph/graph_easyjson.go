@@ -36,8 +36,6 @@ func easyjson2419208eDecodeGithubComAJMBrandsSoftwareThatMattersGraph(in *jlexer
 			continue
 		}
 		switch key {
-		case "timestamp":
-			out.Timestamp = string(in.String())
 		case "dependencies":
 			if in.IsNull() {
 				in.Skip()
@@ -54,6 +52,88 @@ func easyjson2419208eDecodeGithubComAJMBrandsSoftwareThatMattersGraph(in *jlexer
 				}
 				in.Delim('}')
 			}
+		case "devDependencies":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.DevDependencies = make(map[string]string)
+				} else {
+					out.DevDependencies = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v2 string
+					v2 = string(in.String())
+					(out.DevDependencies)[key] = v2
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "optionalDependencies":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.OptionalDependencies = make(map[string]string)
+				} else {
+					out.OptionalDependencies = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v3 string
+					v3 = string(in.String())
+					(out.OptionalDependencies)[key] = v3
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "peerDependencies":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.PeerDependencies = make(map[string]string)
+				} else {
+					out.PeerDependencies = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v4 string
+					v4 = string(in.String())
+					(out.PeerDependencies)[key] = v4
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "testDependencies":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.TestDependencies = make(map[string]string)
+				} else {
+					out.TestDependencies = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v5 string
+					v5 = string(in.String())
+					(out.TestDependencies)[key] = v5
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "timestamp":
+			out.Timestamp = string(in.String())
 		default:
 			in.AddError(&jlexer.LexerError{
 				Offset: in.GetPos(),
@@ -72,32 +152,108 @@ func easyjson2419208eEncodeGithubComAJMBrandsSoftwareThatMattersGraph(out *jwrit
 	out.RawByte('{')
 	first := true
 	_ = first
-	{
-		const prefix string = ",\"timestamp\":"
-		out.RawString(prefix[1:])
-		out.String(string(in.Timestamp))
-	}
 	{
 		const prefix string = ",\"dependencies\":"
-		out.RawString(prefix)
+		out.RawString(prefix[1:])
 		if in.Dependencies == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
 			out.RawString(`null`)
 		} else {
 			out.RawByte('{')
-			v2First := true
-			for v2Name, v2Value := range in.Dependencies {
-				if v2First {
-					v2First = false
+			v6First := true
+			for v6Name, v6Value := range in.Dependencies {
+				if v6First {
+					v6First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v6Name))
+				out.RawByte(':')
+				out.String(string(v6Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	if len(in.DevDependencies) != 0 {
+		const prefix string = ",\"devDependencies\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v7First := true
+			for v7Name, v7Value := range in.DevDependencies {
+				if v7First {
+					v7First = false
 				} else {
 					out.RawByte(',')
 				}
-				out.String(string(v2Name))
+				out.String(string(v7Name))
 				out.RawByte(':')
-				out.String(string(v2Value))
+				out.String(string(v7Value))
 			}
 			out.RawByte('}')
 		}
 	}
+	if len(in.OptionalDependencies) != 0 {
+		const prefix string = ",\"optionalDependencies\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v8First := true
+			for v8Name, v8Value := range in.OptionalDependencies {
+				if v8First {
+					v8First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v8Name))
+				out.RawByte(':')
+				out.String(string(v8Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	if len(in.PeerDependencies) != 0 {
+		const prefix string = ",\"peerDependencies\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v9First := true
+			for v9Name, v9Value := range in.PeerDependencies {
+				if v9First {
+					v9First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v9Name))
+				out.RawByte(':')
+				out.String(string(v9Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	if len(in.TestDependencies) != 0 {
+		const prefix string = ",\"testDependencies\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v10First := true
+			for v10Name, v10Value := range in.TestDependencies {
+				if v10First {
+					v10First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v10Name))
+				out.RawByte(':')
+				out.String(string(v10Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"timestamp\":"
+		out.RawString(prefix)
+		out.String(string(in.Timestamp))
+	}
 	out.RawByte('}')
 }
 
@@ -143,8 +299,6 @@ func easyjson2419208eDecodeGithubComAJMBrandsSoftwareThatMattersGraph1(in *jlexe
 			continue
 		}
 		switch key {
-		case "name":
-			out.Name = string(in.String())
 		case "versions":
 			if in.IsNull() {
 				in.Skip()
@@ -154,9 +308,31 @@ func easyjson2419208eDecodeGithubComAJMBrandsSoftwareThatMattersGraph1(in *jlexe
 				for !in.IsDelim('}') {
 					key := string(in.String())
 					in.WantColon()
-					var v3 VersionInfo
-					(v3).UnmarshalEasyJSON(in)
-					(out.Versions)[key] = v3
+					var v11 VersionInfo
+					(v11).UnmarshalEasyJSON(in)
+					(out.Versions)[key] = v11
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "name":
+			out.Name = string(in.String())
+		case "dist-tags":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.DistTags = make(map[string]string)
+				} else {
+					out.DistTags = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v12 string
+					v12 = string(in.String())
+					(out.DistTags)[key] = v12
 					in.WantComma()
 				}
 				in.Delim('}')
@@ -179,28 +355,47 @@ func easyjson2419208eEncodeGithubComAJMBrandsSoftwareThatMattersGraph1(out *jwri
 	out.RawByte('{')
 	first := true
 	_ = first
-	{
-		const prefix string = ",\"name\":"
-		out.RawString(prefix[1:])
-		out.String(string(in.Name))
-	}
 	{
 		const prefix string = ",\"versions\":"
-		out.RawString(prefix)
+		out.RawString(prefix[1:])
 		if in.Versions == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
 			out.RawString(`null`)
 		} else {
 			out.RawByte('{')
-			v4First := true
-			for v4Name, v4Value := range in.Versions {
-				if v4First {
-					v4First = false
+			v13First := true
+			for v13Name, v13Value := range in.Versions {
+				if v13First {
+					v13First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v13Name))
+				out.RawByte(':')
+				(v13Value).MarshalEasyJSON(out)
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix)
+		out.String(string(in.Name))
+	}
+	if len(in.DistTags) != 0 {
+		const prefix string = ",\"dist-tags\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v14First := true
+			for v14Name, v14Value := range in.DistTags {
+				if v14First {
+					v14First = false
 				} else {
 					out.RawByte(',')
 				}
-				out.String(string(v4Name))
+				out.String(string(v14Name))
 				out.RawByte(':')
-				(v4Value).MarshalEasyJSON(out)
+				out.String(string(v14Value))
 			}
 			out.RawByte('}')
 		}
@@ -250,12 +445,16 @@ func easyjson2419208eDecodeGithubComAJMBrandsSoftwareThatMattersGraph2(in *jlexe
 			continue
 		}
 		switch key {
+		case "Timestamp":
+			out.Timestamp = string(in.String())
+		case "Ecosystem":
+			out.Ecosystem = string(in.String())
 		case "Name":
 			out.Name = string(in.String())
 		case "Version":
 			out.Version = string(in.String())
-		case "Timestamp":
-			out.Timestamp = string(in.String())
+		case "External":
+			out.External = bool(in.Bool())
 		default:
 			in.AddError(&jlexer.LexerError{
 				Offset: in.GetPos(),
@@ -275,8 +474,18 @@ func easyjson2419208eEncodeGithubComAJMBrandsSoftwareThatMattersGraph2(out *jwri
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"Name\":"
+		const prefix string = ",\"Timestamp\":"
 		out.RawString(prefix[1:])
+		out.String(string(in.Timestamp))
+	}
+	{
+		const prefix string = ",\"Ecosystem\":"
+		out.RawString(prefix)
+		out.String(string(in.Ecosystem))
+	}
+	{
+		const prefix string = ",\"Name\":"
+		out.RawString(prefix)
 		out.String(string(in.Name))
 	}
 	{
@@ -285,9 +494,9 @@ func easyjson2419208eEncodeGithubComAJMBrandsSoftwareThatMattersGraph2(out *jwri
 		out.String(string(in.Version))
 	}
 	{
-		const prefix string = ",\"Timestamp\":"
+		const prefix string = ",\"External\":"
 		out.RawString(prefix)
-		out.String(string(in.Timestamp))
+		out.Bool(bool(in.External))
 	}
 	out.RawByte('}')
 }
@@ -350,9 +559,9 @@ func easyjson2419208eDecodeGithubComAJMBrandsSoftwareThatMattersGraph3(in *jlexe
 					out.Pkgs = (out.Pkgs)[:0]
 				}
 				for !in.IsDelim(']') {
-					var v5 PackageInfo
-					(v5).UnmarshalEasyJSON(in)
-					out.Pkgs = append(out.Pkgs, v5)
+					var v15 PackageInfo
+					(v15).UnmarshalEasyJSON(in)
+					out.Pkgs = append(out.Pkgs, v15)
 					in.WantComma()
 				}
 				in.Delim(']')
@@ -382,11 +591,11 @@ func easyjson2419208eEncodeGithubComAJMBrandsSoftwareThatMattersGraph3(out *jwri
 			out.RawString("null")
 		} else {
 			out.RawByte('[')
-			for v6, v7 := range in.Pkgs {
-				if v6 > 0 {
+			for v16, v17 := range in.Pkgs {
+				if v16 > 0 {
 					out.RawByte(',')
 				}
-				(v7).MarshalEasyJSON(out)
+				(v17).MarshalEasyJSON(out)
 			}
 			out.RawByte(']')
 		}
@@ -1,7 +1,12 @@
 package graph
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -31,9 +36,8 @@ func TestNodeCreationBasicGraph(t *testing.T) {
 	}
 	//dummyMap := make(map[int64]NodeInfo)
 	graph := NewDirectedGraph()
-	hashMap, nodeMap := CreateMaps(&simplePackageInfo, graph)
-	hashToVersionMap := CreateHashedVersionMap(&simplePackageInfo)
-	CreateEdges(graph, &simplePackageInfo, hashMap, hashToVersionMap, false)
+	index, nodeMap := CreateMaps(&simplePackageInfo, graph)
+	CreateEdges(context.Background(), graph, &simplePackageInfo, index, nodeMap, SemverDialect)
 
 	t.Run("Create two nodes because we specified two packages", func(t *testing.T) {
 
@@ -46,13 +50,13 @@ func TestNodeCreationBasicGraph(t *testing.T) {
 
 	t.Run("Create the two unique, correct nodes", func(t *testing.T) {
 		var idA, idB int64
-		if a, check := nodeMap[LookupByStringId("A-1.0.0", hashMap)]; check && graph.Node(idA) != nil {
+		if a, check := nodeMap[lookupTestNode(index, NodeKey{Name: "A", Version: "1.0.0"})]; check && graph.Node(idA) != nil {
 			idA = a.id
 		} else {
 			t.Error("Node A-1.0.0 didn't exist")
 		}
 
-		if b, check := nodeMap[LookupByStringId("B-1.0.0", hashMap)]; check && graph.Node(idB) != nil {
+		if b, check := nodeMap[lookupTestNode(index, NodeKey{Name: "B", Version: "1.0.0"})]; check && graph.Node(idB) != nil {
 			idB = b.id
 		} else {
 			t.Error("Node B-1.0.0 didn't exist")
@@ -127,9 +131,8 @@ func TestNodeCreationMediumComplexity(t *testing.T) {
 	}
 
 	graph := NewDirectedGraph()
-	hashMap, nodeMap := CreateMaps(&mediumPackageInfo, graph)
-	hashToVersionMap := CreateHashedVersionMap(&mediumPackageInfo)
-	CreateEdges(graph, &mediumPackageInfo, hashMap, hashToVersionMap, false)
+	index, nodeMap := CreateMaps(&mediumPackageInfo, graph)
+	CreateEdges(context.Background(), graph, &mediumPackageInfo, index, nodeMap, SemverDialect)
 
 	t.Run("Creates 9 nodes, one for every package version", func(t *testing.T) {
 
@@ -163,7 +166,7 @@ func TestNodeCreationMediumComplexity(t *testing.T) {
 		}
 
 		for _, v := range packageIDS {
-			if actual, ok := nodeMap[LookupByStringId(v, hashMap)]; !ok {
+			if actual, ok := nodeMap[lookupTestNode(index, testKey(v))]; !ok {
 				t.Errorf("Package version node %s not found", v)
 			} else {
 				expected := testInfo[v]
@@ -217,9 +220,8 @@ func TestCreateEdgesBasicGraph(t *testing.T) {
 	}
 	//dummyMap := make(map[int64]NodeInfo)
 	graph := NewDirectedGraph()
-	hashMap, nodeMap := CreateMaps(&simplePackagesInfo, graph)
-	hashToVersionMap := CreateHashedVersionMap(&simplePackagesInfo)
-	CreateEdges(graph, &simplePackagesInfo, hashMap, hashToVersionMap, false)
+	index, nodeMap := CreateMaps(&simplePackagesInfo, graph)
+	CreateEdges(context.Background(), graph, &simplePackagesInfo, index, nodeMap, SemverDialect)
 
 	t.Run("Creates one edge when there is one dependency", func(t *testing.T) {
 
@@ -228,8 +230,8 @@ func TestCreateEdgesBasicGraph(t *testing.T) {
 		}
 	})
 	t.Run("Creates the edge with the correct direction (dependent -> dependency)", func(t *testing.T) {
-		fromID := nodeMap[LookupByStringId("B-1.0.0", hashMap)].id
-		toID := nodeMap[LookupByStringId("A-1.0.0", hashMap)].id
+		fromID := nodeMap[lookupTestNode(index, NodeKey{Name: "B", Version: "1.0.0"})].id
+		toID := nodeMap[lookupTestNode(index, NodeKey{Name: "A", Version: "1.0.0"})].id
 		if graph.Edge(fromID, toID) == nil {
 			if graph.Edge(toID, fromID) != nil {
 				t.Error("Expected the correct direction but got a reversed edge. Please check if the edge " +
@@ -290,23 +292,22 @@ func TestCreateEdgesMediumComplexityGraph(t *testing.T) {
 	}
 	//dummyMap := make(map[int64]NodeInfo)
 	graph := NewDirectedGraph()
-	hashMap, nodeMap := CreateMaps(&packagesInfo, graph)
-	hashToVersionMap := CreateHashedVersionMap(&packagesInfo)
-	CreateEdges(graph, &packagesInfo, hashMap, hashToVersionMap, false)
+	index, nodeMap := CreateMaps(&packagesInfo, graph)
+	CreateEdges(context.Background(), graph, &packagesInfo, index, nodeMap, SemverDialect)
 	t.Run("Creates 4 edges when there are 4 possible dependencies", func(t *testing.T) {
 		if graph.Edges().Len() != 4 {
 			t.Errorf("Expected 4 edges, got %d", graph.Edges().Len())
 		}
 	})
 	t.Run("Creates edges to the correct dependencies for Node B-1.0.0", func(t *testing.T) {
-		if graph.From(nodeMap[LookupByStringId("B-1.0.0", hashMap)].id).Len() != 3 {
-			t.Errorf("Expected 3 possible dependencies for Node B-1.0.0, got %d", graph.From(nodeMap[LookupByStringId("B-1.0.0", hashMap)].id).Len())
+		if graph.From(nodeMap[lookupTestNode(index, NodeKey{Name: "B", Version: "1.0.0"})].id).Len() != 3 {
+			t.Errorf("Expected 3 possible dependencies for Node B-1.0.0, got %d", graph.From(nodeMap[lookupTestNode(index, NodeKey{Name: "B", Version: "1.0.0"})].id).Len())
 		}
-		nodesIterator := graph.From(nodeMap[LookupByStringId("B-1.0.0", hashMap)].id)
+		nodesIterator := graph.From(nodeMap[lookupTestNode(index, NodeKey{Name: "B", Version: "1.0.0"})].id)
 		counter := 0
 		for nodesIterator.Next() {
 			currentNode := nodesIterator.Node()
-			if currentNode.ID() == graph.Node(nodeMap[LookupByStringId("C-1.0.0", hashMap)].id).ID() {
+			if currentNode.ID() == graph.Node(nodeMap[lookupTestNode(index, NodeKey{Name: "C", Version: "1.0.0"})].id).ID() {
 				counter++
 			}
 		}
@@ -316,8 +317,183 @@ func TestCreateEdgesMediumComplexityGraph(t *testing.T) {
 
 	})
 	t.Run("Creates no edges from Node A-1.0.0 (it has no dependencies)", func(t *testing.T) {
-		if graph.From(nodeMap[LookupByStringId("A-1.0.0", hashMap)].id).Len() != 0 {
-			t.Errorf("Expected 0 dependencies for Node A-1.0.0, got %d", graph.From(nodeMap[LookupByStringId("A-1.0.0", hashMap)].id).Len())
+		if graph.From(nodeMap[lookupTestNode(index, NodeKey{Name: "A", Version: "1.0.0"})].id).Len() != 0 {
+			t.Errorf("Expected 0 dependencies for Node A-1.0.0, got %d", graph.From(nodeMap[lookupTestNode(index, NodeKey{Name: "A", Version: "1.0.0"})].id).Len())
 		}
 	})
 }
+
+func TestCreateEdgesMaven(t *testing.T) {
+	packagesInfo := []PackageInfo{
+		{
+			Name: "B",
+			Versions: map[string]VersionInfo{
+				"1.0.0": {
+					Timestamp: "2021-04-22T20:15:37",
+					Dependencies: map[string]string{
+						"A": "[1.0,2.0)",
+					},
+				},
+			},
+		},
+		{
+			Name: "A",
+			Versions: map[string]VersionInfo{
+				"0.9": {
+					Timestamp:    "2020-04-01T20:15:37",
+					Dependencies: map[string]string{},
+				},
+				"1.0.RELEASE": {
+					Timestamp:    "2020-05-01T20:15:37",
+					Dependencies: map[string]string{},
+				},
+				"1.10": {
+					Timestamp:    "2021-06-01T20:15:37",
+					Dependencies: map[string]string{},
+				},
+				"2.0-SNAPSHOT": {
+					Timestamp:    "2021-07-01T20:15:37",
+					Dependencies: map[string]string{},
+				},
+				"2.0": {
+					Timestamp:    "2021-08-01T20:15:37",
+					Dependencies: map[string]string{},
+				},
+			},
+		},
+	}
+	graph := NewDirectedGraph()
+	index, nodeMap := CreateMaps(&packagesInfo, graph)
+	CreateEdges(context.Background(), graph, &packagesInfo, index, nodeMap, MavenDialect)
+
+	t.Run("Creates edges to the versions in the Maven range", func(t *testing.T) {
+		fromID := nodeMap[lookupTestNode(index, NodeKey{Name: "B", Version: "1.0.0"})].id
+		for _, v := range []string{"A-1.0.RELEASE", "A-1.10", "A-2.0-SNAPSHOT"} {
+			if !graph.HasEdgeFromTo(fromID, nodeMap[lookupTestNode(index, testKey(v))].id) {
+				t.Errorf("Expected an edge from B-1.0.0 to %s", v)
+			}
+		}
+		if graph.From(fromID).Len() != 3 {
+			t.Errorf("Expected 3 edges, got %d", graph.From(fromID).Len())
+		}
+	})
+}
+
+func TestCreateEdgesPEP440(t *testing.T) {
+	packagesInfo := []PackageInfo{
+		{
+			Name: "B",
+			Versions: map[string]VersionInfo{
+				"1.0": {
+					Timestamp: "2021-04-22T20:15:37",
+					Dependencies: map[string]string{
+						"A": "~=1.4,!=1.5.*",
+					},
+				},
+			},
+		},
+		{
+			Name: "A",
+			Versions: map[string]VersionInfo{
+				"1.3":       {Timestamp: "2020-04-01T20:15:37"},
+				"1.4":       {Timestamp: "2020-05-01T20:15:37"},
+				"1.5.2":     {Timestamp: "2020-06-01T20:15:37"},
+				"1.6.post1": {Timestamp: "2020-07-01T20:15:37"},
+				"1.7rc1":    {Timestamp: "2020-08-01T20:15:37"},
+				"2.0":       {Timestamp: "2021-08-01T20:15:37"},
+			},
+		},
+	}
+	graph := NewDirectedGraph()
+	index, nodeMap := CreateMaps(&packagesInfo, graph)
+	CreateEdges(context.Background(), graph, &packagesInfo, index, nodeMap, PEP440Dialect)
+
+	fromID := nodeMap[lookupTestNode(index, NodeKey{Name: "B", Version: "1.0"})].id
+	for _, v := range []string{"A-1.4", "A-1.6.post1"} {
+		if !graph.HasEdgeFromTo(fromID, nodeMap[lookupTestNode(index, testKey(v))].id) {
+			t.Errorf("Expected an edge from B-1.0 to %s", v)
+		}
+	}
+	if graph.From(fromID).Len() != 2 {
+		t.Errorf("Expected 2 edges, got %d", graph.From(fromID).Len())
+	}
+	e, _ := DependencyEdgeBetween(graph, fromID, nodeMap[lookupTestNode(index, NodeKey{Name: "A", Version: "1.4"})].id)
+	if e.TranslatedConstraint != "~=1.4, !=1.5.*" {
+		t.Errorf("Expected the normalized constraint ~=1.4, !=1.5.*, got %q", e.TranslatedConstraint)
+	}
+}
+
+// testKey returns the key of the test package version name-version, whose ecosystem is empty. The test package names
+// do not contain '-'.
+func testKey(stringId string) NodeKey {
+	name, version, _ := strings.Cut(stringId, "-")
+	return NodeKey{Name: name, Version: version}
+}
+
+func TestCreateGraphMultipleEcosystems(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	npmPath := write("npm.ndjson", `{"name": "A", "versions": {"1.0.0": {"timestamp": "2021-04-22T20:15:37Z", "dependencies": {"B": "^1.0.0"}}}}
+{"name": "B", "versions": {"1.0.0": {"timestamp": "2021-04-01T20:15:37Z", "dependencies": {}}}}`)
+	pypiPath := write("pypi.json", `{"pkgs": [
+	{"name": "A", "versions": {"1.0.0": {"timestamp": "2021-04-22T20:15:37Z", "dependencies": {"B": ">=1.0"}}}},
+	{"name": "B", "versions": {"1.0": {"timestamp": "2021-04-01T20:15:37Z", "dependencies": {}},
+		"2.0": {"timestamp": "2021-04-02T20:15:37Z", "dependencies": {}}}}
+]}`)
+
+	progress := make(map[string][2]int)
+	options := BuildOptions{Progress: ProgressFunc(func(stage string, done, total int) {
+		progress[stage] = [2]int{done, total}
+	})}
+	pg, report, err := CreateGraph(context.Background(), options,
+		Input{Path: npmPath, Dialect: NpmDialect},
+		Input{Path: pypiPath, Ecosystem: "pypi", Dialect: PEP440Dialect},
+	)
+	if err != nil {
+		t.Fatalf("Creating the graph failed: %v", err)
+	}
+
+	npmA := NodeKey{Ecosystem: "npm", Name: "A", Version: "1.0.0"}
+	pypiA := NodeKey{Ecosystem: "pypi", Name: "A", Version: "1.0.0"}
+	if len(pg.Nodes()) != 5 || !reflect.DeepEqual(pg.Ecosystems(), []string{"npm", "pypi"}) {
+		t.Errorf("Expected 5 nodes in npm and pypi, got %d in %v", len(pg.Nodes()), pg.Ecosystems())
+	}
+	for key, expected := range map[NodeKey][]string{npmA: {"npm:B@1.0.0"}, pypiA: {"pypi:B@1.0", "pypi:B@2.0"}} {
+		found, err := pg.Dependencies(key)
+		if err != nil {
+			t.Errorf("Expected a node for %s, got %v", key, err)
+			continue
+		}
+		var dependencies []string
+		for _, dependency := range found {
+			dependencies = append(dependencies, dependency.Key().String())
+		}
+		if !reflect.DeepEqual(dependencies, expected) {
+			t.Errorf("Expected %s to depend on %v, got %v", key, expected, dependencies)
+		}
+	}
+	if _, ok := pg.Lookup("A", "1.0.0"); ok {
+		t.Errorf("Expected A@1.0.0 to be ambiguous between npm and pypi")
+	}
+	if info, ok := pg.Lookup("B", "2.0"); !ok || info.Ecosystem != "pypi" {
+		t.Errorf("Expected B@2.0 to be found in pypi, got %v", info)
+	}
+	if expected := map[string][2]int{StageReading: {4, 0}, StageEdges: {4, 4}}; !reflect.DeepEqual(progress, expected) {
+		t.Errorf("Expected the progress %v, got %v", expected, progress)
+	}
+	if report.Ecosystems["npm"].Packages != 2 || report.Ecosystems["pypi"].Packages != 2 {
+		t.Errorf("Expected a report of 2 packages for both ecosystems, got %s", report)
+	}
+}
+
+// lookupTestNode returns the id of the node with the given key, or 0 when there is none.
+func lookupTestNode(index *NodeIndex, key NodeKey) int64 {
+	id, _ := index.Lookup(key)
+	return id
+}
@@ -0,0 +1,425 @@
+package graph
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/mailru/easyjson/jlexer"
+)
+
+// ErrInputFormat is returned when the input is not a valid packages document.
+var ErrInputFormat = errors.New("invalid input format")
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// OpenInput opens the input file at path. Files compressed with gzip or zstd are decompressed transparently, whatever
+// their extension.
+func OpenInput(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReaderSize(f, 1<<20)
+	// A short file cannot be compressed, so the error of Peek only matters to the decompressors
+	magic, _ := br.Peek(len(zstdMagic))
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("%w: %s: %v", ErrInputFormat, path, err)
+		}
+		return &inputFile{Reader: gz, close: func() error { gz.Close(); return f.Close() }}, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("%w: %s: %v", ErrInputFormat, path, err)
+		}
+		return &inputFile{Reader: zr, close: func() error { zr.Close(); return f.Close() }}, nil
+	default:
+		return &inputFile{Reader: br, close: f.Close}, nil
+	}
+}
+
+// inputFile is a possibly decompressed input file.
+type inputFile struct {
+	io.Reader
+	close func() error
+}
+
+func (f *inputFile) Close() error { return f.close() }
+
+// PackageReader reads the packages of a document of the form {"pkgs": [...]} one at a time, so the document never has
+// to be held in memory as a whole. Every package is decoded with the easyjson lexer.
+type PackageReader struct {
+	r *bufio.Reader
+	// state is 0 before the array of packages, 1 inside it and 2 after it
+	state int
+	count int
+	buf   []byte
+}
+
+// NewPackageReader returns a PackageReader that reads from r.
+func NewPackageReader(r io.Reader) *PackageReader {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReaderSize(r, 1<<20)
+	}
+	return &PackageReader{r: br}
+}
+
+// Read returns the next package. It returns io.EOF once all the packages were read, and a *RecordError when a package
+// cannot be decoded, after which the next package can still be read.
+func (pr *PackageReader) Read() (PackageInfo, error) {
+	if pr.state == 0 {
+		if err := pr.openArray(); err != nil {
+			return PackageInfo{}, err
+		}
+	}
+	if pr.state == 2 {
+		return PackageInfo{}, io.EOF
+	}
+
+	c, err := pr.nextToken()
+	if err != nil {
+		return PackageInfo{}, err
+	}
+	if pr.count > 0 {
+		// The packages after the first one are preceded by a comma
+		if c == ',' {
+			c, err = pr.nextToken()
+			if err != nil {
+				return PackageInfo{}, err
+			}
+		} else if c != ']' {
+			return PackageInfo{}, pr.unexpected(c, "',' or ']'")
+		}
+	}
+	if c == ']' {
+		return PackageInfo{}, pr.closeDocument()
+	}
+	if c != '{' {
+		return PackageInfo{}, pr.unexpected(c, "a package object")
+	}
+
+	if err := pr.readObject(); err != nil {
+		return PackageInfo{}, err
+	}
+	var packageInfo PackageInfo
+	lexer := jlexer.Lexer{Data: pr.buf}
+	packageInfo.UnmarshalEasyJSON(&lexer)
+	pr.count++
+	if err := lexer.Error(); err != nil {
+		// The whole object was read, so the next package can still be read
+		return PackageInfo{}, &RecordError{Record: fmt.Sprintf("package %d", pr.count-1), Err: err}
+	}
+	return packageInfo, nil
+}
+
+// openArray reads the input up to the start of the array of packages.
+func (pr *PackageReader) openArray() error {
+	if c, err := pr.nextToken(); err != nil {
+		return err
+	} else if c != '{' {
+		return pr.unexpected(c, "'{'")
+	}
+	c, err := pr.nextToken()
+	if err != nil {
+		return err
+	}
+	if c == '}' { // An empty document does not have any packages
+		pr.state = 2
+		return nil
+	}
+	if c != '"' {
+		return pr.unexpected(c, "the key \"pkgs\"")
+	}
+	key, err := pr.r.ReadString('"')
+	if err != nil {
+		return pr.eof(err)
+	}
+	if key != `pkgs"` {
+		return fmt.Errorf("%w: unknown field \"%s", ErrInputFormat, key)
+	}
+	if c, err := pr.nextToken(); err != nil {
+		return err
+	} else if c != ':' {
+		return pr.unexpected(c, "':'")
+	}
+	if c, err := pr.nextToken(); err != nil {
+		return err
+	} else if c != '[' {
+		return pr.unexpected(c, "'['")
+	}
+	pr.state = 1
+	return nil
+}
+
+// closeDocument reads the end of the document after the array of packages.
+func (pr *PackageReader) closeDocument() error {
+	c, err := pr.nextToken()
+	if err != nil {
+		return err
+	}
+	if c != '}' {
+		return pr.unexpected(c, "'}' after the packages")
+	}
+	pr.state = 2
+	return io.EOF
+}
+
+// readObject reads an object whose opening brace was already read into buf, including the braces.
+func (pr *PackageReader) readObject() error {
+	pr.buf = append(pr.buf[:0], '{')
+	depth := 1
+	inString, escaped := false, false
+	for depth > 0 {
+		c, err := pr.r.ReadByte()
+		if err != nil {
+			return pr.eof(err)
+		}
+		pr.buf = append(pr.buf, c)
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+		}
+	}
+	return nil
+}
+
+// nextToken returns the next byte that is not whitespace.
+func (pr *PackageReader) nextToken() (byte, error) {
+	for {
+		c, err := pr.r.ReadByte()
+		if err != nil {
+			return 0, pr.eof(err)
+		}
+		if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			return c, nil
+		}
+	}
+}
+
+func (pr *PackageReader) eof(err error) error {
+	if err == io.EOF {
+		return fmt.Errorf("%w: unexpected end of input after %d packages", ErrInputFormat, pr.count)
+	}
+	return err
+}
+
+func (pr *PackageReader) unexpected(c byte, expected string) error {
+	return fmt.Errorf("%w: expected %s after %d packages, found %q", ErrInputFormat, expected, pr.count, c)
+}
+
+// Input is an input file of CreateGraph.
+type Input struct {
+	Path   string
+	Format InputFormat
+	// Ecosystem is the ecosystem of the packages, such as npm or pypi. The name of the dialect is used when it is empty
+	Ecosystem string
+	// Dialect interprets the versions and the constraints of the packages
+	Dialect Dialect
+}
+
+func (input Input) ecosystem() string {
+	if input.Ecosystem == "" {
+		return input.Dialect.Name()
+	}
+	return input.Ecosystem
+}
+
+// InputFormat is the format of an input file.
+type InputFormat uint8
+
+const (
+	// AutoFormat detects the format from the extension of the file, and otherwise from its content
+	AutoFormat InputFormat = iota
+	// DocumentFormat is a single JSON document of the form {"pkgs": [...]}
+	DocumentFormat
+	// NDJSONFormat has one JSON package per line
+	NDJSONFormat
+)
+
+var inputFormatNames = [...]string{
+	AutoFormat:     "auto",
+	DocumentFormat: "json",
+	NDJSONFormat:   "ndjson",
+}
+
+func (f InputFormat) String() string {
+	if int(f) < len(inputFormatNames) {
+		return inputFormatNames[f]
+	}
+	return fmt.Sprintf("InputFormat(%d)", f)
+}
+
+// ParseInputFormat returns the input format with the given name (auto, json or ndjson). jsonl is accepted as an alias
+// of ndjson.
+func ParseInputFormat(name string) (InputFormat, error) {
+	if strings.EqualFold(name, "jsonl") {
+		return NDJSONFormat, nil
+	}
+	for f, n := range inputFormatNames {
+		if strings.EqualFold(n, name) {
+			return InputFormat(f), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown input format %q, expected auto, json or ndjson", name)
+}
+
+// detectInputFormat returns the format of the input file at path. Files ending in .ndjson or .jsonl, possibly
+// followed by a compression extension, have one package per line. The format of other files is detected from their
+// first key: a document starts with "pkgs", and a package does not.
+func detectInputFormat(path string, r *bufio.Reader) InputFormat {
+	name := strings.ToLower(path)
+	for _, compression := range []string{".gz", ".zst", ".zstd"} {
+		name = strings.TrimSuffix(name, compression)
+	}
+	if strings.HasSuffix(name, ".ndjson") || strings.HasSuffix(name, ".jsonl") {
+		return NDJSONFormat
+	}
+
+	start, _ := r.Peek(64)
+	start = bytes.TrimLeft(start, " \t\r\n")
+	if !bytes.HasPrefix(start, []byte("{")) {
+		return DocumentFormat
+	}
+	start = bytes.TrimLeft(start[1:], " \t\r\n")
+	if len(start) == 0 || start[0] == '}' || bytes.HasPrefix(start, []byte(`"pkgs"`)) {
+		return DocumentFormat
+	}
+	return NDJSONFormat
+}
+
+// NDJSONReader reads packages written one per line. Blank lines are skipped.
+type NDJSONReader struct {
+	r    *bufio.Reader
+	line int
+	buf  []byte
+}
+
+// NewNDJSONReader returns an NDJSONReader that reads from r.
+func NewNDJSONReader(r io.Reader) *NDJSONReader {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReaderSize(r, 1<<20)
+	}
+	return &NDJSONReader{r: br}
+}
+
+// Read returns the next package. It returns io.EOF once all the packages were read, and a *RecordError when a line
+// cannot be decoded, after which the next line can still be read.
+func (nr *NDJSONReader) Read() (PackageInfo, error) {
+	for {
+		line, err := nr.readLine()
+		if err != nil && err != io.EOF {
+			return PackageInfo{}, err
+		}
+		nr.line++
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			if err == io.EOF {
+				return PackageInfo{}, io.EOF
+			}
+			continue
+		}
+
+		var packageInfo PackageInfo
+		lexer := jlexer.Lexer{Data: line}
+		packageInfo.UnmarshalEasyJSON(&lexer)
+		if err := lexer.Error(); err != nil {
+			return PackageInfo{}, &RecordError{Record: fmt.Sprintf("line %d", nr.line), Err: err}
+		}
+		return packageInfo, nil
+	}
+}
+
+// readLine returns the next line, however long it is. The line is only valid until the next call.
+func (nr *NDJSONReader) readLine() ([]byte, error) {
+	nr.buf = nr.buf[:0]
+	for {
+		chunk, err := nr.r.ReadSlice('\n')
+		nr.buf = append(nr.buf, chunk...)
+		if err != bufio.ErrBufferFull {
+			return nr.buf, err
+		}
+	}
+}
+
+// packageSource is implemented by PackageReader and NDJSONReader.
+type packageSource interface {
+	Read() (PackageInfo, error)
+}
+
+// ReadPackages reads all the packages of the input file at path, which may be compressed. Every package is passed to
+// visit as soon as it is read. It stops at the first package that cannot be decoded.
+func ReadPackages(path string, format InputFormat, visit func(packageInfo PackageInfo)) error {
+	return readPackages(path, format, func(packageInfo PackageInfo) error {
+		visit(packageInfo)
+		return nil
+	}, nil)
+}
+
+// readPackages reads the packages like ReadPackages, and stops at the first error returned by visit. When skip is not
+// nil, the packages that cannot be decoded are passed to skip instead, and the rest of the input is still read.
+func readPackages(path string, format InputFormat, visit func(packageInfo PackageInfo) error, skip func(err *RecordError)) error {
+	f, err := OpenInput(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// Uncompressed files are already buffered by OpenInput, and only the output of the decompressors needs a buffer
+	br, ok := f.(*inputFile).Reader.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReaderSize(f, 1<<20)
+	}
+	if format == AutoFormat {
+		format = detectInputFormat(path, br)
+	}
+	var source packageSource
+	if format == NDJSONFormat {
+		source = NewNDJSONReader(br)
+	} else {
+		source = NewPackageReader(br)
+	}
+
+	for {
+		packageInfo, err := source.Read()
+		if err == io.EOF {
+			return nil
+		}
+		var recordErr *RecordError
+		if skip != nil && errors.As(err, &recordErr) {
+			skip(recordErr)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if err := visit(packageInfo); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package graph
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/mailru/easyjson"
+)
+
+const inputTestDocument = `{"pkgs": [
+	{"name": "A", "versions": {"1.0.0": {"timestamp": "2020-04-01T20:15:37Z", "dependencies": {}}}},
+	{"name": "B {with} [brackets]", "versions": {"1.0.0": {"timestamp": "2021-04-22T20:15:37Z",
+		"dependencies": {"A": ">=1.0.0", "C\"}": "1.0.0"}}}},
+	{"name": "C", "dist-tags": {"latest": "2.0.0"}, "versions": {}}
+]}`
+
+func TestPackageReader(t *testing.T) {
+	var expected Doc
+	if err := easyjson.Unmarshal([]byte(inputTestDocument), &expected); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	write := func(name string, compress func(w io.Writer) io.WriteCloser) string {
+		path := filepath.Join(dir, name)
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		w := compress(f)
+		if _, err := io.WriteString(w, inputTestDocument); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	paths := []string{
+		write("plain.json", func(w io.Writer) io.WriteCloser { return nopWriteCloser{w} }),
+		write("input.json.gz", func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }),
+		write("input.zst", func(w io.Writer) io.WriteCloser {
+			zw, err := zstd.NewWriter(w)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return zw
+		}),
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			var packages []PackageInfo
+			if err := ReadPackages(path, AutoFormat, func(p PackageInfo) { packages = append(packages, p) }); err != nil {
+				t.Fatalf("Reading the packages failed: %v", err)
+			}
+			if !reflect.DeepEqual(packages, expected.Pkgs) {
+				t.Errorf("Expected %v, got %v", expected.Pkgs, packages)
+			}
+		})
+	}
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestPackageReaderEmptyDocuments(t *testing.T) {
+	for _, document := range []string{`{}`, `{"pkgs": []}`, ` { "pkgs" : [ ] } `} {
+		_, err := NewPackageReader(strings.NewReader(document)).Read()
+		if err != io.EOF {
+			t.Errorf("Expected %q to be empty, got %v", document, err)
+		}
+	}
+}
+
+func TestPackageReaderInvalidDocuments(t *testing.T) {
+	for _, document := range []string{
+		``,
+		`[]`,
+		`{"packages": []}`,
+		`{"pkgs": [{"name": "A"}`,
+		`{"pkgs": [{"name": "A"} {"name": "B"}]}`,
+		`{"pkgs": [{"name": "A", "unknown": 1}]}`,
+		`{"pkgs": [1]}`,
+		`{"pkgs": []`,
+	} {
+		pr := NewPackageReader(strings.NewReader(document))
+		var err error
+		for err == nil {
+			_, err = pr.Read()
+		}
+		if !errors.Is(err, ErrInputFormat) {
+			t.Errorf("Expected %q to be invalid, got %v", document, err)
+		}
+	}
+}
+
+func TestReadPackagesNDJSON(t *testing.T) {
+	var expected Doc
+	if err := easyjson.Unmarshal([]byte(inputTestDocument), &expected); err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, p := range expected.Pkgs {
+		line, err := easyjson.Marshal(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, string(line))
+	}
+	// Blank lines and a missing final newline are accepted
+	input := strings.Join(lines[:2], "\n") + "\n\n  \r\n" + lines[2]
+
+	dir := t.TempDir()
+	for _, test := range []struct {
+		name   string
+		format InputFormat
+	}{
+		{"input.ndjson", AutoFormat},
+		{"input.jsonl", AutoFormat},
+		{"detected.json", AutoFormat},
+		{"chosen.json", NDJSONFormat},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(dir, test.name)
+			if err := os.WriteFile(path, []byte(input), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			var packages []PackageInfo
+			if err := ReadPackages(path, test.format, func(p PackageInfo) { packages = append(packages, p) }); err != nil {
+				t.Fatalf("Reading the packages failed: %v", err)
+			}
+			if !reflect.DeepEqual(packages, expected.Pkgs) {
+				t.Errorf("Expected %v, got %v", expected.Pkgs, packages)
+			}
+		})
+	}
+}
+
+func TestDetectInputFormat(t *testing.T) {
+	for _, test := range []struct {
+		path, content string
+		expected      InputFormat
+	}{
+		{"input.json", ` {"pkgs": []}`, DocumentFormat},
+		{"input.json", `{}`, DocumentFormat},
+		{"input.json", "{\n  \"name\": \"A\"}", NDJSONFormat},
+		{"input.json.gz", `{"name": "A"}`, NDJSONFormat},
+		{"input.NDJSON", `{"pkgs": []}`, NDJSONFormat},
+		{"input.jsonl.zst", `{"pkgs": []}`, NDJSONFormat},
+		{"input.json", ``, DocumentFormat},
+	} {
+		if actual := detectInputFormat(test.path, bufio.NewReader(strings.NewReader(test.content))); actual != test.expected {
+			t.Errorf("Expected %s for %s with %q, got %s", test.expected, test.path, test.content, actual)
+		}
+	}
+}
+
+func TestNDJSONReaderInvalidLines(t *testing.T) {
+	nr := NewNDJSONReader(strings.NewReader("{\"name\": \"A\"}\n\n{\"name\": \"B\", \"unknown\": 1}\n"))
+	if _, err := nr.Read(); err != nil {
+		t.Fatalf("Expected the first line to be valid, got %v", err)
+	}
+	_, err := nr.Read()
+	if !errors.Is(err, ErrInputFormat) || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("Expected an error on line 3, got %v", err)
+	}
+}
+
+func TestParseInputFormat(t *testing.T) {
+	for name, expected := range map[string]InputFormat{"auto": AutoFormat, "JSON": DocumentFormat, "ndjson": NDJSONFormat, "jsonl": NDJSONFormat} {
+		if actual, err := ParseInputFormat(name); err != nil || actual != expected {
+			t.Errorf("Expected %s for %q, got %s (%v)", expected, name, actual, err)
+		}
+	}
+	if _, err := ParseInputFormat("xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,108 @@
+package graph
+
+import (
+	"sort"
+	"strings"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// InstallLayer is a set of package versions that can be installed in parallel once the versions of the previous layers
+// are installed. Every group of a layer is installed as a whole: it is either a single version, or the versions of a
+// dependency cycle, which cannot be installed one after the other.
+type InstallLayer [][]NodeInfo
+
+// String returns the groups of the layer separated by commas, with the versions of a cycle joined by +.
+func (layer InstallLayer) String() string {
+	groups := make([]string, len(layer))
+	for i, group := range layer {
+		keys := make([]string, len(group))
+		for j, node := range group {
+			keys[j] = node.Key().String()
+		}
+		groups[i] = strings.Join(keys, " + ")
+	}
+	return strings.Join(groups, ", ")
+}
+
+// InstallLayers orders a resolved set of package versions, such as the result of GetLatestTransitiveDependenciesNode,
+// in the layers in which they can be built or installed. A version of the set depends on another one when it depends
+// on any version of its package, since the set only keeps one version of every package. The first layer holds the
+// versions that do not depend on any other version of the set, and every following layer the versions whose
+// dependencies are all in the previous layers. The versions of a cycle are collapsed into a single group. The groups
+// of a layer are sorted by name and version.
+func InstallLayers(g graph.Directed, nodeMap map[int64]NodeInfo, resolved []NodeInfo) []InstallLayer {
+	versionsOf := make(map[packageKey][]int64)
+	dependencies := NewDirectedGraph()
+	for _, node := range resolved {
+		if dependencies.Node(node.id) != nil {
+			continue
+		}
+		dependencies.AddNode(Node(node.id))
+		pkg := node.Key().packageKey()
+		versionsOf[pkg] = append(versionsOf[pkg], node.id)
+	}
+	for _, node := range resolved {
+		for to := g.From(node.id); to.Next(); {
+			for _, id := range versionsOf[nodeMap[to.Node().ID()].Key().packageKey()] {
+				if id != node.id {
+					dependencies.SetEdge(Edge{F: Node(node.id), T: Node(id)})
+				}
+			}
+		}
+	}
+
+	// Every component is a group, and the groups are put in layers from the ones without dependencies up
+	components := stronglyConnectedComponents(dependencies)
+	componentOf := make(map[int64]int, len(resolved))
+	for i, component := range components {
+		for _, id := range component {
+			componentOf[id] = i
+		}
+	}
+	remaining := make([]int, len(components))    // The number of components each component depends on
+	dependents := make([][]int, len(components)) // The components that depend on each component
+	for i, component := range components {
+		seen := make(map[int]struct{})
+		for _, id := range component {
+			for to := dependencies.From(id); to.Next(); {
+				j := componentOf[to.Node().ID()]
+				if _, ok := seen[j]; ok || j == i {
+					continue
+				}
+				seen[j] = struct{}{}
+				remaining[i]++
+				dependents[j] = append(dependents[j], i)
+			}
+		}
+	}
+
+	var current []int
+	for i := range components {
+		if remaining[i] == 0 {
+			current = append(current, i)
+		}
+	}
+	var layers []InstallLayer
+	for len(current) > 0 {
+		layer := make(InstallLayer, len(current))
+		var next []int
+		for i, c := range current {
+			group := make([]NodeInfo, len(components[c]))
+			for j, id := range components[c] {
+				group[j] = nodeMap[id]
+			}
+			sort.Slice(group, func(a, b int) bool { return lessNodeInfo(group[a], group[b]) })
+			layer[i] = group
+			for _, dependent := range dependents[c] {
+				if remaining[dependent]--; remaining[dependent] == 0 {
+					next = append(next, dependent)
+				}
+			}
+		}
+		sort.Slice(layer, func(a, b int) bool { return lessNodeInfo(layer[a][0], layer[b][0]) })
+		layers = append(layers, layer)
+		current = next
+	}
+	return layers
+}
@@ -0,0 +1,52 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInstallLayers(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		pg       *PackageGraph
+		root     NodeKey
+		expected []string
+	}{
+		{"Diamond with a cycle", createPathsTestGraph(), testKey("R-1.0.0"),
+			[]string{"T@2.0.0", "X@1.0.0", "Y@1.0.0 + Z@1.0.0", "R@1.0.0"}},
+		{"Cycle first", createCyclesTestGraph(), testKey("f-1.0.0"),
+			[]string{"a@1.0.0 + b@1.0.0", "f@1.0.0"}},
+		{"Root in a cycle", createCyclesTestGraph(), testKey("a-2.0.0"),
+			[]string{"a@2.0.0 + b@2.0.0"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			resolved, err := test.pg.LatestTransitiveDependencies(test.root)
+			if err != nil {
+				t.Fatalf("Resolving %v failed: %v", test.root, err)
+			}
+			var actual []string
+			for _, layer := range test.pg.InstallLayers(resolved) {
+				actual = append(actual, layer.String())
+			}
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("Expected the layers %v, got %v", test.expected, actual)
+			}
+		})
+	}
+
+	t.Run("Independent packages share a layer", func(t *testing.T) {
+		pg := createPathsTestGraph()
+		var resolved []NodeInfo
+		for _, key := range []string{"U-1.0.0", "T-1.0.0", "X-1.0.0"} {
+			info, _ := pg.LookupKey(testKey(key))
+			resolved = append(resolved, info)
+		}
+		var actual []string
+		for _, layer := range pg.InstallLayers(resolved) {
+			actual = append(actual, layer.String())
+		}
+		if expected := []string{"T@1.0.0, U@1.0.0", "X@1.0.0"}; !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Expected the layers %v, got %v", expected, actual)
+		}
+	})
+}
@@ -1,83 +1,446 @@
 package graph
 
-import "regexp"
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
 
-var reg = regexp.MustCompile("((?P<open>[\\(\\[])(?P<bothVer>((?P<firstVer>(0|[1-9]+)(\\.(0|[1-9]+)(\\.(0|[1-9]+))?)?)(?P<comma1>,)(?P<secondVer1>(0|[1-9]+)(\\.(0|[1-9]+)(\\.(0|[1-9]+))?)?)?)|((?P<comma2>,)?(?P<secondVer2>(0|[1-9]+)(\\.(0|[1-9]+)(\\.(0|[1-9]+))?)?)?))(?P<close>[\\)\\]]))|(?P<simplevers>(0|[1-9]+)(\\.(0|[1-9]+)(\\.(0|[1-9]+))?)?)")
+// This file implements the version ordering and the version ranges of Maven, following ComparableVersion and
+// VersionRange from the Maven sources. See https://maven.apache.org/pom.html#version-order-specification and
+// https://maven.apache.org/pom.html#dependency-version-requirement-specification.
 
-func ParseMultipleMavenSemanticVersions(s string) string {
-	var finalResult string
-	chars := []rune(s)
-	openIndex := 0
-	closeIndex := 0
-	for i := 0; i < len(chars); i++ {
-		char := string(chars[i])
-		if char == "(" || char == "[" {
-			openIndex = i
-		}
-		if char == ")" || char == "]" {
-			closeIndex = i
-			if i != len(chars)-1 {
-				finalResult += translateMavenSemver(s[openIndex:closeIndex+1]) + " || "
-			} else {
-				finalResult += translateMavenSemver(s[openIndex : closeIndex+1])
-			}
-		}
+// MavenVersion is a parsed Maven version. Versions are made of items separated by "." and "-", and of the transitions
+// between digits and letters. "-" starts a new sub-list of items, which is compared after the items before it.
+type MavenVersion struct {
+	original string
+	items    mavenListItem
+}
+
+// ParseMavenVersion parses a Maven version. Every string is a valid Maven version, so parsing never fails.
+func ParseMavenVersion(version string) *MavenVersion {
+	return &MavenVersion{original: version, items: parseMavenItems(strings.ToLower(version))}
+}
+
+// Compare returns -1, 0 or 1 when v is respectively lower than, equal to or greater than other.
+func (v *MavenVersion) Compare(other *MavenVersion) int {
+	return v.items.compare(other.items)
+}
+
+// LessThan returns whether v is lower than other.
+func (v *MavenVersion) LessThan(other *MavenVersion) bool {
+	return v.Compare(other) < 0
+}
+
+// Equal returns whether v and other are the same version, such as 1.0 and 1.0.0.
+func (v *MavenVersion) Equal(other *MavenVersion) bool {
+	return v.Compare(other) == 0
+}
+
+// String returns the version exactly as it was parsed.
+func (v *MavenVersion) String() string {
+	return v.original
+}
+
+// mavenItem is one of the items a Maven version is made of: a number, a qualifier or a list of items.
+type mavenItem interface {
+	// compare compares the item to another item, which can be nil when the other version has fewer items
+	compare(other mavenItem) int
+	isNull() bool
+}
 
+// mavenQualifiers lists the well known qualifiers in ascending order. The empty qualifier is the release version.
+var mavenQualifiers = []string{"alpha", "beta", "milestone", "rc", "snapshot", "", "sp"}
+
+// mavenQualifierAliases maps the qualifiers that are equivalent to a well known one.
+var mavenQualifierAliases = map[string]string{
+	"ga":      "",
+	"final":   "",
+	"release": "",
+	"cr":      "rc",
+}
+
+var mavenReleaseIndex = comparableQualifier("")
+
+// comparableQualifier returns a string that sorts the qualifiers in Maven's order. Well known qualifiers sort by their
+// position in mavenQualifiers, and all the other ones sort after them, alphabetically.
+func comparableQualifier(qualifier string) string {
+	for i, q := range mavenQualifiers {
+		if q == qualifier {
+			return fmt.Sprint(i)
+		}
 	}
-	if closeIndex == 0 && openIndex == 0 {
-		return translateMavenSemver(s)
+	return fmt.Sprintf("%d-%s", len(mavenQualifiers), qualifier)
+}
+
+type mavenIntItem struct {
+	value *big.Int
+}
+
+func (i mavenIntItem) isNull() bool {
+	return i.value.Sign() == 0
+}
+
+func (i mavenIntItem) compare(other mavenItem) int {
+	switch o := other.(type) {
+	case nil:
+		if i.isNull() {
+			return 0
+		}
+		return 1
+	case mavenIntItem:
+		return i.value.Cmp(o.value)
+	default: // Numbers are greater than qualifiers and lists
+		return 1
 	}
+}
 
-	return finalResult
+type mavenStringItem struct {
+	value string
 }
 
-func translateMavenSemver(s string) string {
-	match := reg.FindStringSubmatch(s)
-	result := make(map[string]string)
-	var finalResult string
-	for i, name := range reg.SubexpNames() {
-		if i != 0 && name != "" {
-			result[name] = match[i]
+func newMavenStringItem(value string, followedByDigit bool) mavenStringItem {
+	if followedByDigit && len(value) == 1 {
+		// a1 = alpha-1, b1 = beta-1, m1 = milestone-1
+		switch value {
+		case "a":
+			value = "alpha"
+		case "b":
+			value = "beta"
+		case "m":
+			value = "milestone"
 		}
 	}
-	if len(result["close"]) > 0 {
-		if len(result["secondVer2"]) > 0 {
-			if len(result["comma1"]) > 0 || len(result["comma2"]) > 0 {
-				switch result["close"] {
-				case "]":
-					finalResult = "<= " + result["secondVer2"]
-				case ")":
-					finalResult = "< " + result["secondVer2"]
+	if alias, ok := mavenQualifierAliases[value]; ok {
+		value = alias
+	}
+	return mavenStringItem{value: value}
+}
+
+func (s mavenStringItem) isNull() bool {
+	return comparableQualifier(s.value) == mavenReleaseIndex
+}
+
+func (s mavenStringItem) compare(other mavenItem) int {
+	switch o := other.(type) {
+	case nil: // 1-rc < 1, 1-ga = 1, 1-sp > 1
+		return strings.Compare(comparableQualifier(s.value), mavenReleaseIndex)
+	case mavenStringItem:
+		return strings.Compare(comparableQualifier(s.value), comparableQualifier(o.value))
+	default: // Qualifiers are lower than numbers and lists
+		return -1
+	}
+}
+
+type mavenListItem []mavenItem
+
+func (l mavenListItem) isNull() bool {
+	return len(l) == 0
+}
+
+func (l mavenListItem) compare(other mavenItem) int {
+	switch o := other.(type) {
+	case nil:
+		if len(l) == 0 {
+			return 0
+		}
+		return l[0].compare(nil)
+	case mavenIntItem:
+		return -1
+	case mavenStringItem:
+		return 1
+	case mavenListItem:
+		for i := 0; i < len(l) || i < len(o); i++ {
+			var left, right mavenItem
+			if i < len(l) {
+				left = l[i]
+			}
+			if i < len(o) {
+				right = o[i]
+			}
+			var result int
+			if left == nil {
+				if right != nil {
+					result = -right.compare(left)
 				}
 			} else {
-				finalResult = "= " + result["secondVer2"]
+				result = left.compare(right)
 			}
-		} else {
-			if len(result["firstVer"]) > 0 && len(result["secondVer1"]) > 0 {
-				switch result["open"] {
-				case "[":
-					finalResult = ">= " + result["firstVer"] + ", "
-				case "(":
-					finalResult = "> " + result["firstVer"] + ", "
-				}
-				switch result["close"] {
-				case "]":
-					finalResult += "<= " + result["secondVer1"]
-				case ")":
-					finalResult += "< " + result["secondVer1"]
-				}
-			} else if len(result["firstVer"]) > 0 && len(result["secondVer1"]) == 0 {
-				switch result["open"] {
-				case "[":
-					finalResult = ">= " + result["firstVer"]
-				case "(":
-					finalResult = "> " + result["firstVer"]
-				}
+			if result != 0 {
+				return result
 			}
 		}
-	} else {
-		finalResult = ">= " + result["simplevers"]
+		return 0
+	default:
+		return 0
 	}
-	return finalResult
+}
 
+// normalize removes the trailing null items (0, "" and empty lists), so that 1.0.0 is the same as 1.
+func (l mavenListItem) normalize() mavenListItem {
+	for i := len(l) - 1; i >= 0; i-- {
+		if l[i].isNull() {
+			l = append(l[:i], l[i+1:]...)
+		} else if _, ok := l[i].(mavenListItem); !ok {
+			break
+		}
+	}
+	return l
+}
+
+func parseMavenItem(isDigit bool, buf string) mavenItem {
+	if isDigit {
+		value, _ := new(big.Int).SetString(buf, 10)
+		return mavenIntItem{value: value}
+	}
+	return newMavenStringItem(buf, false)
+}
+
+// parseMavenItems splits a lowercase version into its items. The sub-lists are built with pointers into their parents,
+// because they are normalized only once all of their items are known.
+func parseMavenItems(version string) mavenListItem {
+	type list struct {
+		items  mavenListItem
+		parent *list
+		index  int // The index of the list in its parent
+	}
+	root := &list{}
+	current := root
+	var stack []*list
+
+	startSubList := func() {
+		sub := &list{parent: current, index: len(current.items)}
+		current.items = append(current.items, mavenListItem(nil))
+		current = sub
+		stack = append(stack, sub)
+	}
+
+	isDigit := false
+	start := 0
+	for i := 0; i < len(version); i++ {
+		c := version[i]
+		switch {
+		case c == '.':
+			if i == start {
+				current.items = append(current.items, mavenIntItem{value: big.NewInt(0)})
+			} else {
+				current.items = append(current.items, parseMavenItem(isDigit, version[start:i]))
+			}
+			start = i + 1
+		case c == '-':
+			if i == start {
+				current.items = append(current.items, mavenIntItem{value: big.NewInt(0)})
+			} else {
+				current.items = append(current.items, parseMavenItem(isDigit, version[start:i]))
+			}
+			start = i + 1
+			startSubList()
+		case c >= '0' && c <= '9':
+			if !isDigit && i > start {
+				current.items = append(current.items, newMavenStringItem(version[start:i], true))
+				start = i
+				startSubList()
+			}
+			isDigit = true
+		default:
+			if isDigit && i > start {
+				current.items = append(current.items, parseMavenItem(true, version[start:i]))
+				start = i
+				startSubList()
+			}
+			isDigit = false
+		}
+	}
+	if len(version) > start {
+		current.items = append(current.items, parseMavenItem(isDigit, version[start:]))
+	}
+
+	// Normalize from the innermost list outwards, storing each list in its parent once it is complete
+	for i := len(stack) - 1; i >= 0; i-- {
+		sub := stack[i]
+		sub.parent.items[sub.index] = sub.items.normalize()
+	}
+	return root.items.normalize()
+}
+
+// ErrInvalidMavenRange is returned when a Maven version range cannot be parsed.
+var ErrInvalidMavenRange = errors.New("invalid Maven version range")
+
+// mavenRestriction is a single range, such as [1.0,2.0). A nil bound means that the range is unbounded on that side.
+type mavenRestriction struct {
+	lower, upper                   *MavenVersion
+	lowerInclusive, upperInclusive bool
+}
+
+func (r mavenRestriction) contains(v *MavenVersion) bool {
+	if r.lower != nil {
+		cmp := r.lower.Compare(v)
+		if cmp > 0 || (cmp == 0 && !r.lowerInclusive) {
+			return false
+		}
+	}
+	if r.upper != nil {
+		cmp := r.upper.Compare(v)
+		if cmp < 0 || (cmp == 0 && !r.upperInclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r mavenRestriction) String() string {
+	if r.lower != nil && r.upper != nil && r.lowerInclusive && r.upperInclusive && r.lower.Equal(r.upper) {
+		return "= " + r.lower.String()
+	}
+	var parts []string
+	if r.lower != nil {
+		if r.lowerInclusive {
+			parts = append(parts, ">= "+r.lower.String())
+		} else {
+			parts = append(parts, "> "+r.lower.String())
+		}
+	}
+	if r.upper != nil {
+		if r.upperInclusive {
+			parts = append(parts, "<= "+r.upper.String())
+		} else {
+			parts = append(parts, "< "+r.upper.String())
+		}
+	}
+	if len(parts) == 0 {
+		return "*"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// MavenConstraint is a parsed Maven version requirement. It is either a soft requirement (1.0), or a set of ranges
+// ([1.0,2.0), (,1.0],[1.2,) or [1.5]) of which a version must match at least one.
+type MavenConstraint struct {
+	restrictions []mavenRestriction
+}
+
+// ParseMavenConstraint parses a Maven version requirement. Maven treats a soft requirement such as 1.0 as a
+// recommendation that can be overridden, so it is translated to the range [1.0,), which keeps every version that
+// could be picked instead of it.
+func ParseMavenConstraint(spec string) (*MavenConstraint, error) {
+	process := strings.TrimSpace(spec)
+	if process == "" {
+		return nil, fmt.Errorf("%w: empty range", ErrInvalidMavenRange)
+	}
+
+	constraint := &MavenConstraint{}
+	var upperBound *MavenVersion
+	for strings.HasPrefix(process, "[") || strings.HasPrefix(process, "(") {
+		closeParenthesis := strings.IndexByte(process, ')')
+		closeBracket := strings.IndexByte(process, ']')
+		index := closeBracket
+		if closeBracket < 0 || (closeParenthesis >= 0 && closeParenthesis < closeBracket) {
+			index = closeParenthesis
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("%w: unbounded range %q", ErrInvalidMavenRange, spec)
+		}
+
+		restriction, err := parseMavenRestriction(process[:index+1])
+		if err != nil {
+			return nil, err
+		}
+		if upperBound != nil && (restriction.lower == nil || restriction.lower.LessThan(upperBound)) {
+			return nil, fmt.Errorf("%w: ranges overlap in %q", ErrInvalidMavenRange, spec)
+		}
+		constraint.restrictions = append(constraint.restrictions, restriction)
+		upperBound = restriction.upper
+
+		process = strings.TrimSpace(process[index+1:])
+		if strings.HasPrefix(process, ",") {
+			process = strings.TrimSpace(process[1:])
+		}
+	}
+
+	if process != "" {
+		if len(constraint.restrictions) > 0 {
+			return nil, fmt.Errorf("%w: only fully-qualified sets are allowed with multiple ranges in %q", ErrInvalidMavenRange, spec)
+		}
+		if strings.ContainsAny(process, "[](),") {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidMavenRange, spec)
+		}
+		constraint.restrictions = append(constraint.restrictions, mavenRestriction{
+			lower:          ParseMavenVersion(process),
+			lowerInclusive: true,
+		})
+	}
+
+	return constraint, nil
+}
+
+func parseMavenRestriction(spec string) (mavenRestriction, error) {
+	restriction := mavenRestriction{
+		lowerInclusive: strings.HasPrefix(spec, "["),
+		upperInclusive: strings.HasSuffix(spec, "]"),
+	}
+	process := strings.TrimSpace(spec[1 : len(spec)-1])
+
+	index := strings.IndexByte(process, ',')
+	if index < 0 {
+		if !restriction.lowerInclusive || !restriction.upperInclusive {
+			return restriction, fmt.Errorf("%w: single version must be surrounded by [] in %q", ErrInvalidMavenRange, spec)
+		}
+		if process == "" {
+			return restriction, fmt.Errorf("%w: empty range %q", ErrInvalidMavenRange, spec)
+		}
+		version := ParseMavenVersion(process)
+		restriction.lower, restriction.upper = version, version
+		return restriction, nil
+	}
+
+	lowerBound := strings.TrimSpace(process[:index])
+	upperBound := strings.TrimSpace(process[index+1:])
+	if strings.ContainsRune(upperBound, ',') {
+		return restriction, fmt.Errorf("%w: %q", ErrInvalidMavenRange, spec)
+	}
+	if lowerBound == upperBound {
+		return restriction, fmt.Errorf("%w: range cannot have identical boundaries in %q", ErrInvalidMavenRange, spec)
+	}
+	if lowerBound != "" {
+		restriction.lower = ParseMavenVersion(lowerBound)
+	}
+	if upperBound != "" {
+		restriction.upper = ParseMavenVersion(upperBound)
+	}
+	if restriction.lower != nil && restriction.upper != nil && restriction.upper.LessThan(restriction.lower) {
+		return restriction, fmt.Errorf("%w: range defies version ordering in %q", ErrInvalidMavenRange, spec)
+	}
+	return restriction, nil
+}
+
+// Check returns whether the version satisfies the constraint.
+func (c *MavenConstraint) Check(v *MavenVersion) bool {
+	for _, r := range c.restrictions {
+		if r.contains(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the constraint in the syntax of Masterminds/semver, such as ">= 1.0, < 2.0 || = 3.0".
+func (c *MavenConstraint) String() string {
+	parts := make([]string, 0, len(c.restrictions))
+	for _, r := range c.restrictions {
+		parts = append(parts, r.String())
+	}
+	return strings.Join(parts, " || ")
+}
+
+// ParseMultipleMavenSemanticVersions translates a Maven version requirement to the syntax of Masterminds/semver. It
+// returns an empty string when the requirement is not valid.
+//
+// Deprecated: semver cannot order Maven versions correctly. Use ParseMavenConstraint and ParseMavenVersion instead.
+func ParseMultipleMavenSemanticVersions(s string) string {
+	constraint, err := ParseMavenConstraint(s)
+	if err != nil {
+		return ""
+	}
+	return constraint.String()
 }
@@ -0,0 +1,206 @@
+package graph
+
+import (
+	"errors"
+	"testing"
+)
+
+// The versions below come from the examples of the Maven specification and from Maven's ComparableVersionTest.
+// Every list is in strictly ascending order.
+var mavenVersionsQualifier = []string{
+	"1-alpha2snapshot", "1-alpha2", "1-alpha-123", "1-beta-2", "1-beta123", "1-m2", "1-m11", "1-rc", "1-cr2",
+	"1-rc123", "1-SNAPSHOT", "1", "1-sp", "1-sp2", "1-sp123", "1-abc", "1-def", "1-pom-1", "1-1-snapshot",
+	"1-1", "1-2", "1-123",
+}
+
+var mavenVersionsNumber = []string{
+	"2.0", "2-1", "2.0.a", "2.0.0.a", "2.0.2", "2.0.123", "2.1.0", "2.1-a", "2.1b", "2.1-c", "2.1-1", "2.1.0.1",
+	"2.2", "2.123", "11.a2", "11.a11", "11.b2", "11.b11", "11.m2", "11.m11", "11", "11.a", "11b", "11c", "11m",
+}
+
+func TestMavenVersionOrder(t *testing.T) {
+	for _, versions := range [][]string{mavenVersionsQualifier, mavenVersionsNumber} {
+		for i := 0; i < len(versions); i++ {
+			for j := i + 1; j < len(versions); j++ {
+				low, high := ParseMavenVersion(versions[i]), ParseMavenVersion(versions[j])
+				if low.Compare(high) >= 0 {
+					t.Errorf("Expected %s < %s", versions[i], versions[j])
+				}
+				if high.Compare(low) <= 0 {
+					t.Errorf("Expected %s > %s", versions[j], versions[i])
+				}
+			}
+		}
+	}
+}
+
+func TestMavenVersionComparing(t *testing.T) {
+	tests := []struct {
+		low, high string
+	}{
+		{"1", "2"},
+		{"1.5", "2"},
+		{"1", "2.5"},
+		{"1.0", "1.1"},
+		{"1.1", "1.2"},
+		{"1.0.0", "1.1"},
+		{"1.0.1", "1.1"},
+		{"1.1", "1.2.0"},
+		{"1.0-alpha-1", "1.0"},
+		{"1.0-alpha-1", "1.0-alpha-2"},
+		{"1.0-alpha-1", "1.0-beta-1"},
+		{"1.0-beta-1", "1.0-SNAPSHOT"},
+		{"1.0-SNAPSHOT", "1.0"},
+		{"1.0-alpha-1-SNAPSHOT", "1.0-alpha-1"},
+		{"1.0", "1.0-1"},
+		{"1.0-1", "1.0-2"},
+		{"1.0.0", "1.0-1"},
+		{"2.0-1", "2.0.1"},
+		{"2.0.1-klm", "2.0.1-lmn"},
+		{"2.0.1", "2.0.1-xyz"},
+		{"2.0.1", "2.0.1-123"},
+		{"2.0.1-xyz", "2.0.1-123"},
+		{"9", "10"},
+		{"1.9.9", "1.10.0"},
+		{"3.2.1", "10.0.0"},
+		{"4.3.0.RC1", "4.3.0.RELEASE"},
+	}
+	for _, test := range tests {
+		t.Run(test.low+" < "+test.high, func(t *testing.T) {
+			if !ParseMavenVersion(test.low).LessThan(ParseMavenVersion(test.high)) {
+				t.Errorf("Expected %s < %s", test.low, test.high)
+			}
+		})
+	}
+}
+
+func TestMavenVersionsEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"1", "1"},
+		{"1", "1.0"},
+		{"1", "1.0.0"},
+		{"1.0", "1.0.0"},
+		{"1", "1-0"},
+		{"1", "1.0-0"},
+		{"1.0", "1.0-0"},
+		{"1a", "1-a"},
+		{"1a", "1.0-a"},
+		{"1a", "1.0.0-a"},
+		{"1.0a", "1-a"},
+		{"1.0.0a", "1-a"},
+		{"1x", "1-x"},
+		{"1x", "1.0-x"},
+		{"1x", "1.0.0-x"},
+		{"1.0x", "1-x"},
+		{"1.0.0x", "1-x"},
+		{"1ga", "1"},
+		{"1release", "1"},
+		{"1final", "1"},
+		{"1cr", "1rc"},
+		{"1a1", "1-alpha-1"},
+		{"1b2", "1-beta-2"},
+		{"1m3", "1-milestone-3"},
+		{"1X", "1x"},
+		{"1A", "1a"},
+		{"1B", "1b"},
+		{"1M", "1m"},
+		{"1Ga", "1"},
+		{"1GA", "1"},
+		{"1RELEASE", "1"},
+		{"1RELeaSE", "1"},
+		{"1Final", "1"},
+		{"1FINAL", "1"},
+		{"1Cr", "1Rc"},
+		{"1cR", "1rC"},
+		{"1m3", "1Milestone3"},
+		{"1m3", "1MILESTONE3"},
+		{"2.5.RELEASE", "2.5"},
+		{"01.2", "1.2"},
+	}
+	for _, test := range tests {
+		t.Run(test.a+" = "+test.b, func(t *testing.T) {
+			if !ParseMavenVersion(test.a).Equal(ParseMavenVersion(test.b)) {
+				t.Errorf("Expected %s = %s", test.a, test.b)
+			}
+		})
+	}
+}
+
+func TestMavenConstraint(t *testing.T) {
+	tests := []struct {
+		spec       string
+		matches    []string
+		notMatches []string
+	}{
+		// Soft requirements keep every version that could replace them
+		{"1.0", []string{"1.0", "1.0.0", "1.5", "10.0"}, []string{"0.9", "1.0-SNAPSHOT"}},
+		{"[1.0]", []string{"1.0", "1.0.0"}, []string{"1.0.1", "0.9"}},
+		{"(,1.0]", []string{"0.1", "1.0", "1.0-SNAPSHOT"}, []string{"1.0.1", "1.1"}},
+		{"(,1.0)", []string{"0.1", "1.0-SNAPSHOT"}, []string{"1.0", "1.1"}},
+		{"[1.2,1.3]", []string{"1.2", "1.2.5", "1.3"}, []string{"1.1", "1.3.1", "1.2-SNAPSHOT"}},
+		{"[1.0,2.0)", []string{"1.0", "1.10", "1.99.99", "2.0-SNAPSHOT"}, []string{"2.0", "0.9"}},
+		{"[1.5,)", []string{"1.5", "2.0", "10.0"}, []string{"1.4", "1.5-rc1"}},
+		{"(1.5,)", []string{"1.5.1", "2.0"}, []string{"1.5", "1.4"}},
+		{"(,1.0],[1.2,)", []string{"0.5", "1.0", "1.2", "3.0"}, []string{"1.1", "1.0.1"}},
+		{"(,1.1),(1.1,)", []string{"1.0", "1.1.1", "2.0"}, []string{"1.1"}},
+		{"[1.0,1.2],[1.3]", []string{"1.0", "1.2", "1.3"}, []string{"1.2.5", "1.4"}},
+		{" [ 1.0 , 2.0 ) ", []string{"1.0", "1.5"}, []string{"2.0"}},
+		{"[2.5.RELEASE,3.0.0)", []string{"2.5", "2.6.0.RELEASE"}, []string{"3.0.0.RELEASE", "2.5.RC1"}},
+	}
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			constraint, err := ParseMavenConstraint(test.spec)
+			if err != nil {
+				t.Fatalf("Expected %q to be valid, got %v", test.spec, err)
+			}
+			for _, v := range test.matches {
+				if !constraint.Check(ParseMavenVersion(v)) {
+					t.Errorf("Expected %s to match %q", v, test.spec)
+				}
+			}
+			for _, v := range test.notMatches {
+				if constraint.Check(ParseMavenVersion(v)) {
+					t.Errorf("Expected %s not to match %q", v, test.spec)
+				}
+			}
+		})
+	}
+}
+
+func TestMavenConstraintInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"[1.0",
+		"(1.0)",
+		"[1.0)",
+		"[1.0,1.0]",
+		"[2.0,1.0]",
+		"[1.0,2.0],1.5",
+		"[1.0,3.0],[2.0,4.0]",
+		"[1.0,2.0,3.0]",
+	} {
+		t.Run(spec, func(t *testing.T) {
+			if _, err := ParseMavenConstraint(spec); !errors.Is(err, ErrInvalidMavenRange) {
+				t.Errorf("Expected %q to be invalid, got %v", spec, err)
+			}
+		})
+	}
+}
+
+func TestParseMultipleMavenSemanticVersions(t *testing.T) {
+	tests := map[string]string{
+		"1.0":           ">= 1.0",
+		"[1.0]":         "= 1.0",
+		"(,1.0]":        "<= 1.0",
+		"[1.0,2.0)":     ">= 1.0, < 2.0",
+		"(,1.0],[1.2,)": "<= 1.0 || >= 1.2",
+		"[10.0,)":       ">= 10.0",
+	}
+	for spec, expected := range tests {
+		if actual := ParseMultipleMavenSemanticVersions(spec); actual != expected {
+			t.Errorf("Expected %q to translate to %q, got %q", spec, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package graph
+
+// NodeIndex finds the nodes of a graph from their keys, and the versions of every package. The keys are compared in
+// full, so two packages never share a node or a list of versions, whatever their names.
+//
+// The ecosystems and the names of the packages are interned: all the keys of a package share the same strings, which
+// matters with millions of versions. The index also records the dialect of every ecosystem, which orders its versions.
+type NodeIndex struct {
+	nodes    map[NodeKey]int64
+	versions map[packageKey][]string
+	strings  map[string]string
+	dialects map[string]Dialect
+}
+
+// NewNodeIndex returns an empty NodeIndex.
+func NewNodeIndex() *NodeIndex {
+	return &NodeIndex{
+		nodes:    make(map[NodeKey]int64),
+		versions: make(map[packageKey][]string),
+		strings:  make(map[string]string),
+		dialects: make(map[string]Dialect),
+	}
+}
+
+// Add indexes the node under its key. It returns false, and leaves the index as it is, when another node already has
+// the same key. The versions of external nodes are not listed in Versions, since they are not versions of a package of
+// the registry.
+func (index *NodeIndex) Add(nodeInfo NodeInfo) bool {
+	key := index.intern(nodeInfo.Key())
+	if _, ok := index.nodes[key]; ok {
+		return false
+	}
+	index.nodes[key] = nodeInfo.id
+	if !nodeInfo.External {
+		index.versions[key.packageKey()] = append(index.versions[key.packageKey()], key.Version)
+	}
+	return true
+}
+
+// Lookup returns the id of the node with the given key.
+func (index *NodeIndex) Lookup(key NodeKey) (int64, bool) {
+	id, ok := index.nodes[key]
+	return id, ok
+}
+
+// Versions returns the versions of the package of the given ecosystem, in the order they were added.
+func (index *NodeIndex) Versions(ecosystem, name string) []string {
+	return index.versions[packageKey{ecosystem: ecosystem, name: name}]
+}
+
+// SetDialect records the dialect that the versions and the constraints of the ecosystem were read with.
+func (index *NodeIndex) SetDialect(ecosystem string, dialect Dialect) {
+	index.dialects[ecosystem] = dialect
+}
+
+// Dialect returns the dialect that the versions of the ecosystem were read with, or semver when none was recorded.
+func (index *NodeIndex) Dialect(ecosystem string) Dialect {
+	if dialect, ok := index.dialects[ecosystem]; ok {
+		return dialect
+	}
+	return SemverDialect
+}
+
+// Len returns the number of nodes in the index.
+func (index *NodeIndex) Len() int {
+	return len(index.nodes)
+}
+
+func (index *NodeIndex) intern(key NodeKey) NodeKey {
+	for _, s := range []*string{&key.Ecosystem, &key.Name} {
+		if interned, ok := index.strings[*s]; ok {
+			*s = interned
+		} else {
+			index.strings[*s] = *s
+		}
+	}
+	return key
+}
@@ -0,0 +1,58 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNodeIndex(t *testing.T) {
+	index := NewNodeIndex()
+	// These keys all give foo-bar-1.0 when written as name-version
+	nodes := []NodeInfo{
+		{Name: "foo-bar", Version: "1.0", id: 1},
+		{Name: "foo", Version: "bar-1.0", id: 2},
+		{Ecosystem: "npm", Name: "foo-bar", Version: "1.0", id: 3},
+		{Ecosystem: "npm", Name: "foo-bar", Version: "2.0", id: 4},
+		{Ecosystem: "npm", Name: "foo-bar", Version: "github:user/foo-bar", External: true, id: 5},
+	}
+	for _, node := range nodes {
+		if !index.Add(node) {
+			t.Errorf("Expected %s to be added", node.Key())
+		}
+	}
+
+	t.Run("Finds every node from its key", func(t *testing.T) {
+		for _, node := range nodes {
+			if id, ok := index.Lookup(node.Key()); !ok || id != node.id {
+				t.Errorf("Expected %s to point to %d, got %d", node.Key(), node.id, id)
+			}
+		}
+		if _, ok := index.Lookup(NodeKey{Ecosystem: "pypi", Name: "foo-bar", Version: "1.0"}); ok {
+			t.Error("Expected no node in another ecosystem")
+		}
+		if index.Len() != len(nodes) {
+			t.Errorf("Expected %d nodes, got %d", len(nodes), index.Len())
+		}
+	})
+
+	t.Run("Lists the versions of the registry", func(t *testing.T) {
+		if versions := index.Versions("npm", "foo-bar"); !reflect.DeepEqual(versions, []string{"1.0", "2.0"}) {
+			t.Errorf("Expected the versions 1.0 and 2.0, got %v", versions)
+		}
+		if versions := index.Versions("", "foo"); !reflect.DeepEqual(versions, []string{"bar-1.0"}) {
+			t.Errorf("Expected the version bar-1.0, got %v", versions)
+		}
+	})
+
+	t.Run("Keeps the first node of a key", func(t *testing.T) {
+		if index.Add(NodeInfo{Name: "foo-bar", Version: "1.0", id: 6}) {
+			t.Error("Expected a duplicate key to be rejected")
+		}
+		if id, _ := index.Lookup(NodeKey{Name: "foo-bar", Version: "1.0"}); id != 1 {
+			t.Errorf("Expected foo-bar@1.0 to still point to 1, got %d", id)
+		}
+		if versions := index.Versions("", "foo-bar"); len(versions) != 1 {
+			t.Errorf("Expected a single version, got %v", versions)
+		}
+	})
+}
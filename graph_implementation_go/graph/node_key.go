@@ -0,0 +1,35 @@
+package graph
+
+import (
+	"fmt"
+)
+
+// NodeKey identifies a node of the graph: a version of a package in an ecosystem. Unlike the string name-version, it
+// stays unambiguous when names or versions contain '-', and the same package can be part of several ecosystems.
+type NodeKey struct {
+	Ecosystem string
+	Name      string
+	Version   string
+}
+
+// String returns the key as ecosystem:name@version, or name@version when the ecosystem is empty.
+func (key NodeKey) String() string {
+	if key.Ecosystem == "" {
+		return fmt.Sprintf("%s@%s", key.Name, key.Version)
+	}
+	return fmt.Sprintf("%s:%s@%s", key.Ecosystem, key.Name, key.Version)
+}
+
+// Key returns the key of the node.
+func (nodeInfo NodeInfo) Key() NodeKey {
+	return NodeKey{Ecosystem: nodeInfo.Ecosystem, Name: nodeInfo.Name, Version: nodeInfo.Version}
+}
+
+// packageKey identifies a package in an ecosystem.
+type packageKey struct {
+	ecosystem, name string
+}
+
+func (key NodeKey) packageKey() packageKey {
+	return packageKey{ecosystem: key.Ecosystem, name: key.Name}
+}
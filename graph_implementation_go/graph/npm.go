@@ -0,0 +1,375 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+// This file implements the dependency specifications of npm. Version ranges follow node-semver, including its rules
+// for pre-releases, and the other kinds of specifications (dist-tags, aliases, git repositories, urls and local paths)
+// are recognized the way npm-package-arg does. See https://github.com/npm/node-semver#ranges.
+
+// ErrInvalidNpmRange is returned when a constraint is not a valid node-semver range.
+var ErrInvalidNpmRange = errors.New("invalid npm range")
+
+// npmComparator is a primitive comparator such as >=1.2.3 or <2.0.0-0.
+type npmComparator struct {
+	operator string // one of <, <=, >, >= and =
+	version  *semver.Version
+}
+
+func (c npmComparator) test(v *semver.Version) bool {
+	cmp := v.Compare(c.version)
+	switch c.operator {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	default:
+		return cmp == 0
+	}
+}
+
+func (c npmComparator) String() string {
+	return c.operator + c.version.String()
+}
+
+// NpmRange is a parsed node-semver range, such as "^1.2.3 || 2.x" or "1.0.0 - 1.5". A range is a union of comparator
+// sets, and a version is in the range when it satisfies all the comparators of one of the sets.
+type NpmRange struct {
+	// sets holds the comparator sets. An empty set matches every version
+	sets [][]npmComparator
+}
+
+var (
+	npmOrPattern         = regexp.MustCompile(`\s*\|\|\s*`)
+	npmHyphenPattern     = regexp.MustCompile(`^(\S+)\s+-\s+(\S+)$`)
+	npmOperatorSpacing   = regexp.MustCompile(`(<=|>=|~>|<|>|=|~|\^)\s+`)
+	npmComparatorPattern = regexp.MustCompile(`^(<=|>=|~>|<|>|=|~|\^)?(.+)$`)
+	npmPartialPattern    = regexp.MustCompile(`^[v=\s]*` +
+		`(\d+|[xX*])(?:\.(\d+|[xX*])(?:\.(\d+|[xX*])` +
+		`(?:-?([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?` +
+		`(?:\+[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?)?)?$`)
+)
+
+// ParseNpmRange parses a node-semver range. The empty string and * match every version that is not a pre-release.
+func ParseNpmRange(r string) (*NpmRange, error) {
+	result := &NpmRange{}
+	for _, set := range npmOrPattern.Split(strings.TrimSpace(r), -1) {
+		comparators, err := parseNpmComparatorSet(set)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrInvalidNpmRange, r, err)
+		}
+		result.sets = append(result.sets, comparators)
+	}
+	return result, nil
+}
+
+func parseNpmComparatorSet(set string) ([]npmComparator, error) {
+	if match := npmHyphenPattern.FindStringSubmatch(set); match != nil {
+		from, err := parseNpmPartial(match[1])
+		if err != nil {
+			return nil, err
+		}
+		to, err := parseNpmPartial(match[2])
+		if err != nil {
+			return nil, err
+		}
+		return buildNpmComparators(hyphenRange(from, to))
+	}
+
+	comparators := []npmComparator{}
+	for _, token := range strings.Fields(npmOperatorSpacing.ReplaceAllString(set, "$1")) {
+		match := npmComparatorPattern.FindStringSubmatch(token)
+		p, err := parseNpmPartial(match[2])
+		if err != nil {
+			return nil, err
+		}
+		desugared, err := buildNpmComparators(desugarNpmComparator(match[1], p))
+		if err != nil {
+			return nil, err
+		}
+		comparators = append(comparators, desugared...)
+	}
+	return comparators, nil
+}
+
+// npmPartial is a version that may have missing or wildcard components, such as 1.2, 1.x or *.
+type npmPartial struct {
+	major, minor, patch uint64
+	// wildcards is the number of missing or wildcard components, counted from the patch: 0 for 1.2.3, 1 for 1.2.x,
+	// 2 for 1.x and 3 for *. The components after a wildcard are ignored, so 1.x.3 is 1.x
+	wildcards  int
+	prerelease string
+}
+
+// parseNpmPartial parses a partial version. The components are at most math.MaxInt64, like the components of the
+// versions of Masterminds/semver, so that the bounds computed from them cannot overflow.
+func parseNpmPartial(s string) (npmPartial, error) {
+	match := npmPartialPattern.FindStringSubmatch(s)
+	if match == nil {
+		return npmPartial{}, fmt.Errorf("invalid version %q", s)
+	}
+	var p npmPartial
+	components := []*uint64{&p.major, &p.minor, &p.patch}
+	for i, component := range match[1:4] {
+		if component == "" || component == "x" || component == "X" || component == "*" {
+			p.wildcards = 3 - i
+			return p, nil
+		}
+		n, err := strconv.ParseInt(component, 10, 64)
+		if err != nil {
+			return npmPartial{}, fmt.Errorf("invalid version %q", s)
+		}
+		*components[i] = uint64(n)
+	}
+	p.prerelease = match[4]
+	return p, nil
+}
+
+// npmBound is a comparator whose version is only built by buildNpmComparators, since the components of a bound can be
+// too large for a version, as in ^9223372036854775807.
+type npmBound struct {
+	operator            string
+	major, minor, patch uint64
+	prerelease          string
+}
+
+// buildNpmComparators builds the versions of the bounds. An error is returned when a version is invalid.
+func buildNpmComparators(bounds []npmBound) ([]npmComparator, error) {
+	comparators := make([]npmComparator, 0, len(bounds))
+	for _, b := range bounds {
+		s := fmt.Sprintf("%d.%d.%d", b.major, b.minor, b.patch)
+		if b.prerelease != "" {
+			s += "-" + b.prerelease
+		}
+		v, err := semver.NewVersion(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q: %v", s, err)
+		}
+		comparators = append(comparators, npmComparator{operator: b.operator, version: v})
+	}
+	return comparators, nil
+}
+
+// lower returns the bound on the lowest version that the partial matches, such as 1.2.0 for 1.2.x.
+func (p npmPartial) lower(operator string) npmBound {
+	return npmBound{operator, p.major, p.minor, p.patch, p.prerelease}
+}
+
+// next returns the bound on the lowest version after all the versions that the partial matches, such as 1.3.0 for
+// 1.2.x. It must not be called on *.
+func (p npmPartial) next(operator, prerelease string) npmBound {
+	switch p.wildcards {
+	case 0:
+		return npmBound{operator, p.major, p.minor, p.patch + 1, prerelease}
+	case 1:
+		return npmBound{operator, p.major, p.minor + 1, 0, prerelease}
+	default:
+		return npmBound{operator, p.major + 1, 0, 0, prerelease}
+	}
+}
+
+// npmNothing is the bound that no version satisfies.
+var npmNothing = []npmBound{{operator: "<", prerelease: "0"}}
+
+// desugarNpmComparator translates a comparator that may use ~, ^ or wildcards into the bounds of primitive
+// comparators. Upper bounds get a -0 pre-release so that they also exclude the pre-releases of the bound, as
+// node-semver does.
+func desugarNpmComparator(operator string, p npmPartial) []npmBound {
+	if p.wildcards == 3 {
+		switch operator {
+		case "<", ">":
+			return npmNothing
+		default:
+			return nil
+		}
+	}
+
+	switch operator {
+	case "~", "~>":
+		if p.wildcards == 2 {
+			return []npmBound{p.lower(">="), p.next("<", "0")}
+		}
+		return []npmBound{p.lower(">="), {"<", p.major, p.minor + 1, 0, "0"}}
+	case "^":
+		var upper npmBound
+		switch {
+		case p.major != 0 || p.wildcards == 2:
+			upper = npmBound{"<", p.major + 1, 0, 0, "0"}
+		case p.minor != 0 || p.wildcards == 1:
+			upper = npmBound{"<", 0, p.minor + 1, 0, "0"}
+		default:
+			upper = npmBound{"<", 0, 0, p.patch + 1, "0"}
+		}
+		return []npmBound{p.lower(">="), upper}
+	case "", "=":
+		if p.wildcards == 0 {
+			return []npmBound{p.lower("=")}
+		}
+		return []npmBound{p.lower(">="), p.next("<", "0")}
+	case ">":
+		if p.wildcards == 0 {
+			return []npmBound{p.lower(">")}
+		}
+		return []npmBound{p.next(">=", "")}
+	case "<=":
+		if p.wildcards == 0 {
+			return []npmBound{p.lower("<=")}
+		}
+		return []npmBound{p.next("<", "0")}
+	case "<":
+		if p.wildcards == 0 {
+			return []npmBound{p.lower("<")}
+		}
+		return []npmBound{{"<", p.major, p.minor, p.patch, "0"}}
+	default: // >=
+		return []npmBound{p.lower(">=")}
+	}
+}
+
+// hyphenRange translates the range from - to, where both ends are inclusive.
+func hyphenRange(from, to npmPartial) []npmBound {
+	bounds := []npmBound{}
+	if from.wildcards < 3 {
+		bounds = append(bounds, from.lower(">="))
+	}
+	switch {
+	case to.wildcards == 0:
+		bounds = append(bounds, to.lower("<="))
+	case to.wildcards < 3:
+		bounds = append(bounds, to.next("<", "0"))
+	}
+	return bounds
+}
+
+// Check returns whether the version is in the range. A pre-release is only in the range when a comparator of the
+// matching set has a pre-release of the same major, minor and patch, so ^1.2.3-beta.1 matches 1.2.3-beta.2 but not
+// 1.2.4-beta.1.
+func (r *NpmRange) Check(v *semver.Version) bool {
+	for _, set := range r.sets {
+		if testNpmComparatorSet(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func testNpmComparatorSet(set []npmComparator, v *semver.Version) bool {
+	for _, c := range set {
+		if !c.test(v) {
+			return false
+		}
+	}
+	if v.Prerelease() == "" {
+		return true
+	}
+	for _, c := range set {
+		allowed := c.version
+		if allowed.Prerelease() != "" && allowed.Major() == v.Major() && allowed.Minor() == v.Minor() &&
+			allowed.Patch() == v.Patch() {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the range with all its comparators translated to primitive comparators.
+func (r *NpmRange) String() string {
+	sets := make([]string, len(r.sets))
+	for i, set := range r.sets {
+		if len(set) == 0 {
+			sets[i] = "*"
+			continue
+		}
+		comparators := make([]string, len(set))
+		for j, c := range set {
+			comparators[j] = c.String()
+		}
+		sets[i] = strings.Join(comparators, " ")
+	}
+	return strings.Join(sets, " || ")
+}
+
+var (
+	npmTagPattern       = regexp.MustCompile(`^[A-Za-z0-9\-_.!~*'()]+$`)
+	npmGitPattern       = regexp.MustCompile(`^(?:git\+|git:|git@|github:|gitlab:|bitbucket:|gist:)|\.git(?:#.*)?$`)
+	npmShorthandPattern = regexp.MustCompile(`^[^@/\s:#.~][^/\s:#]*/[^/\s:#]+(?:#.*)?$`)
+	npmLocalPattern     = regexp.MustCompile(`^(?:file:|link:|\.|~/|/|\\|[a-zA-Z]:)|\.(?:tgz|tar\.gz|tar)$`)
+)
+
+type npmDialect struct{}
+
+type npmConstraint struct {
+	r *NpmRange
+}
+
+func (npmDialect) Name() string { return "npm" }
+
+func (npmDialect) ParseVersion(version string) (Version, error) {
+	return SemverDialect.ParseVersion(version)
+}
+
+func (npmDialect) ParseConstraint(constraint string) (Constraint, error) {
+	r, err := ParseNpmRange(constraint)
+	if err != nil {
+		return nil, err
+	}
+	return npmConstraint{r: r}, nil
+}
+
+func (npmDialect) Compare(a, b Version) int {
+	return SemverDialect.Compare(a, b)
+}
+
+// ParseSpec recognizes the kinds of specifications that npm accepts in a package.json. Aliases (npm:name@range)
+// constrain the versions of another package, and names that are not ranges are dist-tags. Git repositories, urls and
+// local paths do not point to the registry.
+func (d npmDialect) ParseSpec(name, spec string) (DependencySpec, error) {
+	spec = strings.TrimSpace(spec)
+	switch {
+	case strings.HasPrefix(spec, "npm:"):
+		alias := strings.TrimPrefix(spec, "npm:")
+		// The @ of a scope is not the separator of the range
+		at := strings.LastIndex(alias, "@")
+		if at <= 0 {
+			return DependencySpec{Name: alias, Tag: "latest"}, nil
+		}
+		target, err := d.ParseSpec(alias[:at], alias[at+1:])
+		if err != nil || target.Source != RegistrySource {
+			return DependencySpec{}, fmt.Errorf("invalid npm alias %q", spec)
+		}
+		return target, nil
+	case npmGitPattern.MatchString(spec) || npmShorthandPattern.MatchString(spec):
+		return DependencySpec{Name: name, Source: GitSource}, nil
+	case strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://"):
+		return DependencySpec{Name: name, Source: URLSource}, nil
+	case npmLocalPattern.MatchString(spec):
+		return DependencySpec{Name: name, Source: LocalSource}, nil
+	}
+
+	constraint, err := d.ParseConstraint(spec)
+	if err == nil {
+		return DependencySpec{Name: name, Constraint: constraint}, nil
+	}
+	if npmTagPattern.MatchString(spec) {
+		return DependencySpec{Name: name, Tag: spec}, nil
+	}
+	return DependencySpec{}, err
+}
+
+func (c npmConstraint) Check(v Version) bool {
+	sv, ok := v.(*semver.Version)
+	return ok && c.r.Check(sv)
+}
+
+func (c npmConstraint) String() string { return c.r.String() }
@@ -0,0 +1,192 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver"
+)
+
+// Most of the ranges below come from the range tests of node-semver.
+func TestNpmRange(t *testing.T) {
+	tests := []struct {
+		r          string
+		matches    []string
+		notMatches []string
+	}{
+		{"", []string{"0.0.1", "1.2.3"}, []string{"1.2.3-beta"}},
+		{"*", []string{"1.2.3"}, []string{"1.2.3-beta"}},
+		{"1.2.3", []string{"1.2.3", "v1.2.3"}, []string{"1.2.4"}},
+		{"=1.2.3", []string{"1.2.3"}, []string{"1.2.2"}},
+		{"1.0.0 - 2.0.0", []string{"1.0.0", "1.2.3", "2.0.0"}, []string{"2.0.1", "0.9.9"}},
+		{"1.2 - 2.3", []string{"1.2.0", "2.3.9"}, []string{"2.4.0", "1.1.9"}},
+		{"1.x || >=2.5.0 || 5.0.0 - 7.2.3", []string{"1.9.9", "2.5.0", "7.2.4"}, []string{"2.0.0", "2.4.9"}},
+		{"1.x", []string{"1.0.0", "1.9.9"}, []string{"2.0.0", "2.0.0-0", "1.2.0-beta"}},
+		{"1.2.*", []string{"1.2.0", "1.2.9"}, []string{"1.3.0", "1.1.9"}},
+		{"~1.2.3", []string{"1.2.3", "1.2.9"}, []string{"1.3.0", "1.2.2"}},
+		{"~1.2", []string{"1.2.0", "1.2.9"}, []string{"1.3.0"}},
+		{"~1", []string{"1.0.0", "1.9.9"}, []string{"2.0.0"}},
+		{"~> 1.2", []string{"1.2.5"}, []string{"1.3.0"}},
+		{"~1.2.3-beta.2", []string{"1.2.3-beta.4", "1.2.4"}, []string{"1.2.4-beta.2", "1.2.3-beta.1"}},
+		{"^1.2.3", []string{"1.2.3", "1.9.9"}, []string{"2.0.0", "1.2.2", "2.0.0-alpha"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"^0.0", []string{"0.0.0", "0.0.9"}, []string{"0.1.0"}},
+		{"^0", []string{"0.0.1", "0.9.0"}, []string{"1.0.0"}},
+		{"^1.2.3-beta.2", []string{"1.2.3-beta.4", "1.9.0"}, []string{"1.2.4-beta.2", "1.2.3-alpha"}},
+		{">1.2", []string{"1.3.0"}, []string{"1.2.9"}},
+		{">=1.2", []string{"1.2.0"}, []string{"1.1.9"}},
+		{"<1.2", []string{"1.1.9"}, []string{"1.2.0", "1.2.0-0"}},
+		{"<=1.2", []string{"1.2.9"}, []string{"1.3.0"}},
+		{">= 1.0.0 < 2", []string{"1.5.0"}, []string{"2.0.0"}},
+		{"<*", nil, []string{"0.0.0", "1.0.0"}},
+		{">*", nil, []string{"1.0.0"}},
+		{">=1.0.0-rc.1 <1.0.1", []string{"1.0.0-rc.2", "1.0.0"}, []string{"1.0.1-rc.1", "1.0.0-beta"}},
+	}
+	for _, test := range tests {
+		t.Run(test.r, func(t *testing.T) {
+			r, err := ParseNpmRange(test.r)
+			if err != nil {
+				t.Fatalf("Expected %q to be valid, got %v", test.r, err)
+			}
+			for _, v := range test.matches {
+				if !r.Check(semver.MustParse(v)) {
+					t.Errorf("Expected %s to match %q (%s)", v, test.r, r)
+				}
+			}
+			for _, v := range test.notMatches {
+				if r.Check(semver.MustParse(v)) {
+					t.Errorf("Expected %s not to match %q (%s)", v, test.r, r)
+				}
+			}
+		})
+	}
+}
+
+func TestNpmRangeInvalid(t *testing.T) {
+	for _, r := range []string{"latest", ">=", "1.2.3.4", "^a.b", "git+https://example.com/a.git",
+		"^9223372036854775807", "18446744073709551615", "^0.0.18446744073709551615", ">9223372036854775807.x"} {
+		if _, err := ParseNpmRange(r); !errors.Is(err, ErrInvalidNpmRange) {
+			t.Errorf("Expected %q to be invalid, got %v", r, err)
+		}
+	}
+}
+
+func TestNpmSpec(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected DependencySpec
+	}{
+		{"^1.0.0", DependencySpec{Name: "a"}},
+		{"latest", DependencySpec{Name: "a", Tag: "latest"}},
+		{"next", DependencySpec{Name: "a", Tag: "next"}},
+		{"npm:b@^2.0.0", DependencySpec{Name: "b"}},
+		{"npm:@scope/b@beta", DependencySpec{Name: "@scope/b", Tag: "beta"}},
+		{"npm:b", DependencySpec{Name: "b", Tag: "latest"}},
+		{"git+https://github.com/user/a.git#v1.0.0", DependencySpec{Name: "a", Source: GitSource}},
+		{"git://github.com/user/a.git", DependencySpec{Name: "a", Source: GitSource}},
+		{"github:user/a", DependencySpec{Name: "a", Source: GitSource}},
+		{"user/a#semver:^1.0.0", DependencySpec{Name: "a", Source: GitSource}},
+		{"https://example.com/a-1.0.0.tgz", DependencySpec{Name: "a", Source: URLSource}},
+		{"file:../a", DependencySpec{Name: "a", Source: LocalSource}},
+		{"link:../a", DependencySpec{Name: "a", Source: LocalSource}},
+		{"./a-1.0.0.tgz", DependencySpec{Name: "a", Source: LocalSource}},
+	}
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			spec, err := NpmDialect.(SpecDialect).ParseSpec("a", test.spec)
+			if err != nil {
+				t.Fatalf("Expected %q to be valid, got %v", test.spec, err)
+			}
+			if spec.Name != test.expected.Name || spec.Tag != test.expected.Tag || spec.Source != test.expected.Source {
+				t.Errorf("Expected %+v, got %+v", test.expected, spec)
+			}
+			if isRange := spec.Tag == "" && spec.Source == RegistrySource; isRange != (spec.Constraint != nil) {
+				t.Errorf("Expected a constraint only for ranges, got %v", spec.Constraint)
+			}
+		})
+	}
+}
+
+func TestCreateEdgesNpmHugeVersions(t *testing.T) {
+	packagesInfo := []PackageInfo{
+		{Name: "A", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2021-04-22T20:15:37Z", Dependencies: map[string]string{"B": "^9223372036854775807"}},
+		}},
+		{Name: "B", Versions: map[string]VersionInfo{"1.0.0": {Timestamp: "2021-04-01T20:15:37Z"}}},
+	}
+	graph := NewDirectedGraph()
+	index, nodeMap := CreateMaps(&packagesInfo, graph)
+	report, err := CreateEdges(context.Background(), graph, &packagesInfo, index, nodeMap, NpmDialect)
+	if err != nil {
+		t.Fatalf("Creating the edges failed: %v", err)
+	}
+	if e := report.Ecosystems["npm"]; e == nil || e.UnparseableConstraints != 1 {
+		t.Errorf("Expected the constraint to be reported as unparseable, got %+v", e)
+	}
+}
+
+func TestCreateEdgesNpm(t *testing.T) {
+	packagesInfo := []PackageInfo{
+		{
+			Name: "B",
+			Versions: map[string]VersionInfo{
+				"1.0.0": {
+					Timestamp: "2021-04-22T20:15:37Z",
+					Dependencies: map[string]string{
+						"A": "next",
+						"C": "npm:A@~1.0.0",
+						"D": "github:user/d#main",
+					},
+				},
+			},
+		},
+		{
+			Name: "A",
+			Versions: map[string]VersionInfo{
+				"1.0.0":       {Timestamp: "2020-04-01T20:15:37Z"},
+				"1.0.1-rc.1":  {Timestamp: "2020-05-01T20:15:37Z"},
+				"2.0.0-beta1": {Timestamp: "2020-06-01T20:15:37Z"},
+			},
+			DistTags: map[string]string{"latest": "1.0.0", "next": "2.0.0-beta1"},
+		},
+	}
+	graph := NewDirectedGraph()
+	index, nodeMap := CreateMaps(&packagesInfo, graph)
+	CreateEdges(context.Background(), graph, &packagesInfo, index, nodeMap, NpmDialect)
+
+	bID := nodeMap[lookupTestNode(index, NodeKey{Name: "B", Version: "1.0.0"})].id
+	tests := []struct {
+		to         string
+		constraint string
+		source     DependencySource
+	}{
+		{"A-2.0.0-beta1", "next", RegistrySource},
+		{"A-1.0.0", "npm:A@~1.0.0", RegistrySource},
+		{"D-github:user/d#main", "github:user/d#main", GitSource},
+	}
+	for _, test := range tests {
+		e, ok := DependencyEdgeBetween(graph, bID, lookupTestNode(index, testKey(test.to)))
+		if !ok {
+			t.Errorf("Expected an edge from B-1.0.0 to %s", test.to)
+			continue
+		}
+		if e.Constraint != test.constraint || e.Source != test.source {
+			t.Errorf("Expected the edge to %s to be %s from %s, got %v", test.to, test.constraint, test.source, e)
+		}
+	}
+	if graph.From(bID).Len() != len(tests) {
+		t.Errorf("Expected %d edges, got %d", len(tests), graph.From(bID).Len())
+	}
+
+	external := nodeMap[lookupTestNode(index, NodeKey{Name: "D", Version: "github:user/d#main"})]
+	if !external.External || external.Name != "D" {
+		t.Errorf("Expected D to be an external node, got %+v", external)
+	}
+	view := FilterView(graph, nodeMap, time.Time{}, time.Now())
+	if view.Node(external.id) != nil {
+		t.Error("Expected the external node to be filtered out")
+	}
+}
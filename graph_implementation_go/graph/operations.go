@@ -2,21 +2,20 @@ package graph
 
 import (
 	"fmt"
-	"github.com/Masterminds/semver"
 	"gonum.org/v1/gonum/graph"
-	"gonum.org/v1/gonum/graph/network"
 	"gonum.org/v1/gonum/graph/traverse"
+	"sort"
 	"time"
 )
 
 // GetTransitiveDependenciesNode returns the specified node and its dependencies
-func GetTransitiveDependenciesNode(g *DirectedGraph, nodeMap map[int64]NodeInfo, hashMap map[uint64]int64, stringId string) *[]NodeInfo {
+func GetTransitiveDependenciesNode(g graph.Directed, nodeMap map[int64]NodeInfo, index *NodeIndex, key NodeKey) *[]NodeInfo {
 	var nodeId int64
 	result := make([]NodeInfo, 0, len(nodeMap)/2)
-	if id, ok := findNode(hashMap, nodeMap, stringId); ok {
+	if id, ok := findNode(index, nodeMap, key); ok && g.Node(id) != nil {
 		nodeId = id
 	} else {
-		return &result // This function is a no-op if we don't have a correct string id
+		return &result // This function is a no-op if we don't have a correct key or the node was filtered out
 	}
 
 	w := traverse.BreadthFirst{
@@ -29,11 +28,115 @@ func GetTransitiveDependenciesNode(g *DirectedGraph, nodeMap map[int64]NodeInfo,
 	return &result
 }
 
+// Dependency is a direct dependency of a node, together with the edge that explains why the node depends on it.
+type Dependency struct {
+	NodeInfo
+	Edge DependencyEdge
+}
+
+func (dependency Dependency) String() string {
+	return fmt.Sprintf("%v - Constraint: %v", dependency.NodeInfo, dependency.Edge)
+}
+
+// GetDirectDependenciesNode returns the direct dependencies of the specified node, sorted by name and version, together
+// with the constraint and the kind of the edges that lead to them.
+func GetDirectDependenciesNode(g graph.Directed, nodeMap map[int64]NodeInfo, index *NodeIndex, key NodeKey) *[]Dependency {
+	result := make([]Dependency, 0)
+	nodeId, ok := findNode(index, nodeMap, key)
+	if !ok || g.Node(nodeId) == nil {
+		return &result // This function is a no-op if we don't have a correct key or the node was filtered out
+	}
+
+	result = directDependencies(g, nodeMap, nodeId)
+	return &result
+}
+
+// directDependencies returns the direct dependencies of the node with the given id, sorted by name and version, with
+// the edges that lead to them.
+func directDependencies(g graph.Directed, nodeMap map[int64]NodeInfo, nodeId int64) []Dependency {
+	result := make([]Dependency, 0)
+	for dependencies := g.From(nodeId); dependencies.Next(); {
+		dependencyId := dependencies.Node().ID()
+		edge, _ := DependencyEdgeBetween(g, nodeId, dependencyId)
+		result = append(result, Dependency{NodeInfo: nodeMap[dependencyId], Edge: edge})
+	}
+	sortDependencies(result)
+	return result
+}
+
+// sortDependencies sorts the dependencies by name and version, since the iteration order of the graph is not fixed.
+func sortDependencies(dependencies []Dependency) {
+	sort.Slice(dependencies, func(i, j int) bool {
+		if dependencies[i].Name != dependencies[j].Name {
+			return dependencies[i].Name < dependencies[j].Name
+		}
+		return dependencies[i].Version < dependencies[j].Version
+	})
+}
+
+// GetDirectDependentsNode returns the nodes that depend directly on the specified node, sorted by name and version,
+// together with the constraint and the kind of the edges that lead from them. Like GetTransitiveDependentsNode, it
+// enables the reverse index of the graph.
+func GetDirectDependentsNode(g graph.Directed, nodeMap map[int64]NodeInfo, index *NodeIndex, key NodeKey) *[]Dependency {
+	result := make([]Dependency, 0)
+	nodeId, ok := findNode(index, nodeMap, key)
+	if !ok || g.Node(nodeId) == nil {
+		return &result // This function is a no-op if we don't have a correct key or the node was filtered out
+	}
+
+	if ri, ok := g.(reverseIndexer); ok {
+		ri.EnableReverseIndex()
+	}
+
+	result = directDependents(g, nodeMap, nodeId)
+	return &result
+}
+
+// directDependents returns the nodes that depend directly on the node with the given id, sorted by name and version,
+// with the edges that lead from them. The graph must implement To.
+func directDependents(g graph.Directed, nodeMap map[int64]NodeInfo, nodeId int64) []Dependency {
+	result := make([]Dependency, 0)
+	for dependents := g.To(nodeId); dependents.Next(); {
+		dependentId := dependents.Node().ID()
+		edge, _ := DependencyEdgeBetween(g, dependentId, nodeId)
+		result = append(result, Dependency{NodeInfo: nodeMap[dependentId], Edge: edge})
+	}
+	sortDependencies(result)
+	return result
+}
+
+// GetTransitiveDependentsNode returns the specified node and all the nodes that depend on it, directly or transitively.
+// This answers the question "if this package version is broken, what breaks with it?". The reverse index of the graph
+// is enabled if it was not already, which is expensive the first time on large graphs. Graphs that do not build a
+// reverse index on demand must implement To.
+func GetTransitiveDependentsNode(g graph.Directed, nodeMap map[int64]NodeInfo, index *NodeIndex, key NodeKey) *[]NodeInfo {
+	var nodeId int64
+	result := make([]NodeInfo, 0)
+	if id, ok := findNode(index, nodeMap, key); ok && g.Node(id) != nil {
+		nodeId = id
+	} else {
+		return &result // This function is a no-op if we don't have a correct key or the node was filtered out
+	}
+
+	if ri, ok := g.(reverseIndexer); ok {
+		ri.EnableReverseIndex()
+	}
+
+	w := traverse.BreadthFirst{
+		Visit: func(n graph.Node) {
+			result = append(result, nodeMap[n.ID()])
+		},
+	}
+
+	_ = w.Walk(reversedGraph{g: g}, g.Node(nodeId), nil)
+	return &result
+}
+
 // GetLatestTransitiveDependenciesNode gets the latest dependencies matching the node's version constraints.
 // If interested in finding this within a specific timeframe, use FilterNoTraversal first
-func GetLatestTransitiveDependenciesNode(g *DirectedGraph, nodeMap map[int64]NodeInfo, hashMap map[uint64]int64, stringId string) *[]NodeInfo {
+func GetLatestTransitiveDependenciesNode(g graph.Directed, nodeMap map[int64]NodeInfo, index *NodeIndex, key NodeKey) *[]NodeInfo {
 	var rootNode NodeInfo
-	allDeps := GetTransitiveDependenciesNode(g, nodeMap, hashMap, stringId)
+	allDeps := GetTransitiveDependenciesNode(g, nodeMap, index, key)
 	result := make([]NodeInfo, 0, len(*allDeps)/2)
 	if len(*allDeps) > 1 {
 		rootNode = (*allDeps)[0]
@@ -41,7 +144,7 @@ func GetLatestTransitiveDependenciesNode(g *DirectedGraph, nodeMap map[int64]Nod
 		return &result // No-op if no dependencies were found for whatever reason
 	}
 
-	newestPackageVersion := make(map[uint32]NodeInfo, len(*allDeps)/2)
+	newestPackageVersion := make(map[packageKey]NodeInfo, len(*allDeps)/2)
 
 	result = append(result, rootNode)
 
@@ -52,28 +155,23 @@ func GetLatestTransitiveDependenciesNode(g *DirectedGraph, nodeMap map[int64]Nod
 			continue
 		}
 
-		hash := hashPackageName(current.Name)
+		pkg := current.Key().packageKey()
 		currentDate, err := time.Parse(time.RFC3339, current.Timestamp)
 		if err != nil {
 			continue
 		}
-		if latest, ok := newestPackageVersion[hash]; ok {
-			latestDate, err := time.Parse(time.RFC3339, latest.Timestamp)
-			if err != nil {
-				fmt.Println(err)
-				continue
-			} else if currentDate.After(latestDate) { // If the key exists, and current date is later than the one stored
-				newestPackageVersion[hash] = current // Set to the current package
+		if latest, ok := newestPackageVersion[pkg]; ok {
+			// Only versions with a valid timestamp are stored, so the timestamp of latest always parses
+			latestDate, _ := time.Parse(time.RFC3339, latest.Timestamp)
+			if currentDate.After(latestDate) { // If the key exists, and current date is later than the one stored
+				newestPackageVersion[pkg] = current // Set to the current package
 			} else if currentDate.Equal(latestDate) { // If the dates are somehow equal, compare version numbers
-				currentVersion, _ := semver.NewVersion(current.Version)
-				latestVersion, _ := semver.NewVersion(latest.Version)
-
-				if currentVersion.GreaterThan(latestVersion) {
-					newestPackageVersion[hash] = current
+				if isNewerVersion(index, current, latest) {
+					newestPackageVersion[pkg] = current
 				}
 			}
 		} else { // If the key doesn't exist yet
-			newestPackageVersion[hash] = current
+			newestPackageVersion[pkg] = current
 		}
 	}
 
@@ -84,13 +182,80 @@ func GetLatestTransitiveDependenciesNode(g *DirectedGraph, nodeMap map[int64]Nod
 	return &result
 }
 
-// PageRank uses the sparse page rank algorithm to find the Page ranks of all nodes
-func PageRank(graph *DirectedGraph) map[int64]float64 {
-	pr := network.PageRankSparse(graph, 0.85, 0.001)
-	return pr
+// ResolveAsOf resolves the dependencies of the specified node the way a package manager would have resolved them at
+// time t. At each hop, the highest version of every dependency that satisfies the constraint and was released before t
+// is picked. Every package is resolved only once, by the first node that depends on it in breadth-first order.
+// The result starts with the root node and is empty if the root itself was released after t.
+func ResolveAsOf(g graph.Directed, nodeMap map[int64]NodeInfo, index *NodeIndex, key NodeKey, t time.Time) *[]NodeInfo {
+	result := make([]NodeInfo, 0)
+	rootId, ok := findNode(index, nodeMap, key)
+	if !ok || g.Node(rootId) == nil || !releasedBefore(nodeMap[rootId], t) {
+		return &result // This function is a no-op if we don't have a correct key or the root did not exist yet
+	}
+
+	resolvedPackages := map[packageKey]struct{}{nodeMap[rootId].Key().packageKey(): {}}
+	queue := []int64{rootId}
+	for len(queue) > 0 {
+		current := nodeMap[queue[0]]
+		queue = queue[1:]
+		result = append(result, current)
+
+		// The edges of a node point to every version that satisfies the constraint, so picking the highest one
+		// released before t for every package name is enough to resolve the dependency
+		candidates := make(map[packageKey]NodeInfo)
+		var order []packageKey
+		for dependencies := g.From(current.id); dependencies.Next(); {
+			dependency := nodeMap[dependencies.Node().ID()]
+			if !releasedBefore(dependency, t) {
+				continue
+			}
+			pkg := dependency.Key().packageKey()
+			if _, ok := resolvedPackages[pkg]; ok {
+				continue
+			}
+			if best, ok := candidates[pkg]; !ok {
+				candidates[pkg] = dependency
+				order = append(order, pkg)
+			} else if isNewerVersion(index, dependency, best) {
+				candidates[pkg] = dependency
+			}
+		}
+
+		// Sorting keeps the result deterministic, since the iteration order of From is not
+		sort.Slice(order, func(i, j int) bool { return candidates[order[i]].Name < candidates[order[j]].Name })
+		for _, pkg := range order {
+			resolvedPackages[pkg] = struct{}{}
+			queue = append(queue, candidates[pkg].id)
+		}
+	}
+
+	return &result
 }
 
-func Betweenness(graph *DirectedGraph) map[int64]float64 {
-	betweenness := network.Betweenness(graph)
-	return betweenness
+// releasedBefore returns whether the node was released at or before time t. Nodes with invalid timestamps are
+// considered to be released after any time.
+func releasedBefore(node NodeInfo, t time.Time) bool {
+	released, err := time.Parse(time.RFC3339, node.Timestamp)
+	if err != nil {
+		return false
+	}
+	return !released.After(t)
+}
+
+// isNewerVersion returns whether current has a higher version than other, as ordered by the dialect that their
+// ecosystem was read with. The versions that the dialect cannot parse are older than the ones it can parse, and are
+// ordered by their release dates, so that isNewerVersion can be used to sort the versions of a package.
+func isNewerVersion(index *NodeIndex, current, other NodeInfo) bool {
+	dialect := index.Dialect(current.Ecosystem)
+	currentVersion, currentErr := dialect.ParseVersion(current.Version)
+	otherVersion, otherErr := dialect.ParseVersion(other.Version)
+	if currentErr == nil && otherErr == nil {
+		return dialect.Compare(currentVersion, otherVersion) > 0
+	}
+	if currentErr == nil || otherErr == nil {
+		return currentErr == nil
+	}
+	currentDate, _ := time.Parse(time.RFC3339, current.Timestamp)
+	otherDate, _ := time.Parse(time.RFC3339, other.Timestamp)
+	return currentDate.After(otherDate)
 }
@@ -0,0 +1,190 @@
+package graph
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func createDependentsTestGraph() (*DirectedGraph, *NodeIndex, map[int64]NodeInfo) {
+	packagesInfo := []PackageInfo{
+		{
+			Name: "B",
+			Versions: map[string]VersionInfo{
+				"1.0.0": {
+					Timestamp: "2021-04-22T20:15:37Z",
+					Dependencies: map[string]string{
+						"A": "1.0.0",
+					},
+				},
+			},
+		},
+		{
+			Name: "C",
+			Versions: map[string]VersionInfo{
+				"1.0.0": {
+					Timestamp: "2022-04-22T20:13:34Z",
+					Dependencies: map[string]string{
+						"B": "1.0.0",
+					},
+				},
+			},
+		},
+		{
+			Name: "A",
+			Versions: map[string]VersionInfo{
+				"1.0.0": {
+					Timestamp:    "2021-04-01T20:15:37Z",
+					Dependencies: map[string]string{},
+				},
+				"2.0.0": {
+					Timestamp:    "2021-06-01T20:15:37Z",
+					Dependencies: map[string]string{},
+				},
+			},
+		},
+	}
+	graph := NewDirectedGraph()
+	index, nodeMap := CreateMaps(&packagesInfo, graph)
+	CreateEdges(context.Background(), graph, &packagesInfo, index, nodeMap, SemverDialect)
+	return graph, index, nodeMap
+}
+
+func TestGetTransitiveDependentsNode(t *testing.T) {
+	graph, index, nodeMap := createDependentsTestGraph()
+
+	t.Run("Finds the direct and transitive dependents of A-1.0.0", func(t *testing.T) {
+		dependents := GetTransitiveDependentsNode(graph, nodeMap, index, testKey("A-1.0.0"))
+		found := make(map[string]bool)
+		for _, n := range *dependents {
+			found[n.Name+"-"+n.Version] = true
+		}
+		for _, expected := range []string{"A-1.0.0", "B-1.0.0", "C-1.0.0"} {
+			if !found[expected] {
+				t.Errorf("Expected %s to be in the dependents, got %v", expected, *dependents)
+			}
+		}
+		if len(*dependents) != 3 {
+			t.Errorf("Expected 3 nodes, got %d", len(*dependents))
+		}
+	})
+
+	t.Run("Finds no dependents for A-2.0.0", func(t *testing.T) {
+		dependents := GetTransitiveDependentsNode(graph, nodeMap, index, testKey("A-2.0.0"))
+		if len(*dependents) != 1 {
+			t.Errorf("Expected only the node itself, got %v", *dependents)
+		}
+	})
+
+	t.Run("Keeps the reverse index up to date when removing nodes", func(t *testing.T) {
+		bID := nodeMap[lookupTestNode(index, NodeKey{Name: "B", Version: "1.0.0"})].id
+		aID := nodeMap[lookupTestNode(index, NodeKey{Name: "A", Version: "1.0.0"})].id
+		graph.RemoveNode(bID)
+		if graph.To(aID).Len() != 0 {
+			t.Errorf("Expected no dependents for A-1.0.0 after removing B-1.0.0, got %d", graph.To(aID).Len())
+		}
+	})
+}
+
+func TestResolveAsOf(t *testing.T) {
+	packagesInfo := []PackageInfo{
+		{
+			Name: "B",
+			Versions: map[string]VersionInfo{
+				"1.0.0": {
+					Timestamp: "2021-04-22T20:15:37Z",
+					Dependencies: map[string]string{
+						"A": ">=0.9.0",
+						"C": "1.0.0",
+					},
+				},
+			},
+		},
+		{
+			Name: "C",
+			Versions: map[string]VersionInfo{
+				"1.0.0": {
+					Timestamp: "2021-05-22T20:13:34Z",
+					Dependencies: map[string]string{
+						"A": "<1.0.0",
+					},
+				},
+			},
+		},
+		{
+			Name: "A",
+			Versions: map[string]VersionInfo{
+				"0.9.0": {
+					Timestamp:    "2020-04-01T20:15:37Z",
+					Dependencies: map[string]string{},
+				},
+				"1.0.0": {
+					Timestamp:    "2021-06-01T20:15:37Z",
+					Dependencies: map[string]string{},
+				},
+				"1.1.0": {
+					Timestamp:    "2021-07-01T20:15:37Z",
+					Dependencies: map[string]string{},
+				},
+			},
+		},
+	}
+	graph := NewDirectedGraph()
+	index, nodeMap := CreateMaps(&packagesInfo, graph)
+	CreateEdges(context.Background(), graph, &packagesInfo, index, nodeMap, SemverDialect)
+
+	versionsOf := func(nodes *[]NodeInfo) map[string]string {
+		result := make(map[string]string)
+		for _, n := range *nodes {
+			result[n.Name] = n.Version
+		}
+		return result
+	}
+
+	t.Run("Picks the highest version released before the date", func(t *testing.T) {
+		resolved := versionsOf(ResolveAsOf(graph, nodeMap, index, testKey("B-1.0.0"), time.Date(2021, 6, 15, 0, 0, 0, 0, time.UTC)))
+		if resolved["A"] != "1.0.0" || resolved["C"] != "1.0.0" || len(resolved) != 3 {
+			t.Errorf("Expected B-1.0.0, A-1.0.0 and C-1.0.0, got %v", resolved)
+		}
+	})
+
+	t.Run("Leaves out dependencies that were not released yet", func(t *testing.T) {
+		resolved := versionsOf(ResolveAsOf(graph, nodeMap, index, testKey("B-1.0.0"), time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)))
+		if resolved["A"] != "0.9.0" || len(resolved) != 2 {
+			t.Errorf("Expected B-1.0.0 and A-0.9.0, got %v", resolved)
+		}
+	})
+
+	t.Run("Returns nothing when the root was not released yet", func(t *testing.T) {
+		resolved := ResolveAsOf(graph, nodeMap, index, testKey("B-1.0.0"), time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+		if len(*resolved) != 0 {
+			t.Errorf("Expected no result, got %v", *resolved)
+		}
+	})
+}
+
+func TestIsNewerVersion(t *testing.T) {
+	index := NewNodeIndex()
+	index.SetDialect("pypi", PEP440Dialect)
+	index.SetDialect("maven-central", MavenDialect)
+	version := func(ecosystem, version, timestamp string) NodeInfo {
+		return NodeInfo{Ecosystem: ecosystem, Name: "A", Version: version, Timestamp: timestamp}
+	}
+	for _, test := range []struct {
+		name           string
+		current, other NodeInfo
+		expected       bool
+	}{
+		{"Semver", version("", "1.10.0", "2020-01-01T00:00:00Z"), version("", "1.9.0", "2021-01-01T00:00:00Z"), true},
+		{"PEP 440 release candidate", version("pypi", "1.0", "2020-01-01T00:00:00Z"), version("pypi", "1.0rc1", "2021-01-01T00:00:00Z"), true},
+		{"PEP 440 post release", version("pypi", "1.0.post1", "2020-01-01T00:00:00Z"), version("pypi", "1.0", "2021-01-01T00:00:00Z"), true},
+		{"Maven snapshot", version("maven-central", "1.0-SNAPSHOT", "2021-01-01T00:00:00Z"), version("maven-central", "1.0", "2020-01-01T00:00:00Z"), false},
+		{"Unparseable version", version("", "latest", "2021-01-01T00:00:00Z"), version("", "1.0.0", "2020-01-01T00:00:00Z"), false},
+		{"Parseable version", version("", "1.0.0", "2020-01-01T00:00:00Z"), version("", "latest", "2021-01-01T00:00:00Z"), true},
+		{"Unparseable versions", version("", "b", "2021-01-01T00:00:00Z"), version("", "a", "2020-01-01T00:00:00Z"), true},
+	} {
+		if actual := isNewerVersion(index, test.current, test.other); actual != test.expected {
+			t.Errorf("%s: expected isNewerVersion(%s, %s) to be %v", test.name, test.current.Version, test.other.Version, test.expected)
+		}
+	}
+}
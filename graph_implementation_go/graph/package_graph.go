@@ -0,0 +1,300 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// PackageGraph is a dependency graph together with the information of its nodes and their index, so they cannot get
+// out of sync. Filtering a PackageGraph returns another PackageGraph that shares the information and the index of the
+// nodes, but only sees part of the graph.
+type PackageGraph struct {
+	directed   graph.Directed
+	nodes      map[int64]NodeInfo
+	index      *NodeIndex
+	ecosystems []string
+}
+
+// NewPackageGraph returns the PackageGraph made of the directed graph, the index of its nodes and their information,
+// as created by CreateMaps and CreateEdges.
+func NewPackageGraph(directed graph.Directed, index *NodeIndex, nodes map[int64]NodeInfo) *PackageGraph {
+	seen := make(map[string]struct{})
+	var ecosystems []string
+	for _, info := range nodes {
+		if _, ok := seen[info.Ecosystem]; !ok {
+			seen[info.Ecosystem] = struct{}{}
+			ecosystems = append(ecosystems, info.Ecosystem)
+		}
+	}
+	sort.Strings(ecosystems)
+	return &PackageGraph{directed: directed, nodes: nodes, index: index, ecosystems: ecosystems}
+}
+
+// Directed returns the directed graph, or the view of it that the filters of the PackageGraph selected, for the gonum
+// algorithms.
+func (pg *PackageGraph) Directed() graph.Directed {
+	return pg.directed
+}
+
+// EnableReverseIndex builds the reverse index of the graph, which the queries of the dependents need. They build it
+// themselves the first time, so it only has to be enabled beforehand when the graph is queried concurrently.
+func (pg *PackageGraph) EnableReverseIndex() {
+	if ri, ok := pg.directed.(reverseIndexer); ok {
+		ri.EnableReverseIndex()
+	}
+}
+
+// Ecosystems returns the names of the ecosystems of the packages, in alphabetical order.
+func (pg *PackageGraph) Ecosystems() []string {
+	return pg.ecosystems
+}
+
+// Node returns the information of the node with the given id, if the node is in the graph.
+func (pg *PackageGraph) Node(id int64) (NodeInfo, bool) {
+	info, ok := pg.nodes[id]
+	if !ok || pg.directed.Node(id) == nil {
+		return NodeInfo{}, false
+	}
+	return info, true
+}
+
+// Nodes returns the information of all the nodes in the graph, sorted by id.
+func (pg *PackageGraph) Nodes() []NodeInfo {
+	result := make([]NodeInfo, 0, len(pg.nodes))
+	for nodes := pg.directed.Nodes(); nodes.Next(); {
+		if info, ok := pg.nodes[nodes.Node().ID()]; ok {
+			result = append(result, info)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].id < result[j].id })
+	return result
+}
+
+// LookupKey returns the node with the given key, if it is in the graph.
+func (pg *PackageGraph) LookupKey(key NodeKey) (NodeInfo, bool) {
+	id, ok := pg.index.Lookup(key)
+	if !ok {
+		return NodeInfo{}, false
+	}
+	return pg.Node(id)
+}
+
+// Lookup returns the given version of the package with the given name. The package is looked up in every ecosystem, and
+// is only found when a single ecosystem has that version; use LookupKey to select the ecosystem.
+func (pg *PackageGraph) Lookup(name, version string) (NodeInfo, bool) {
+	var found NodeInfo
+	matches := 0
+	for _, ecosystem := range pg.ecosystems {
+		if info, ok := pg.LookupKey(NodeKey{Ecosystem: ecosystem, Name: name, Version: version}); ok {
+			found = info
+			matches++
+		}
+	}
+	return found, matches == 1
+}
+
+// Versions returns all the versions of the package with the given name in the graph, in every ecosystem. They are
+// sorted by ecosystem, then by release date, with the versions released at the same time ordered by the dialect of
+// their ecosystem. The versions whose timestamp cannot be parsed come last, and external nodes are left out.
+func (pg *PackageGraph) Versions(name string) []NodeInfo {
+	type release struct {
+		info     NodeInfo
+		released time.Time
+		valid    bool
+	}
+	var result []NodeInfo
+	for _, ecosystem := range pg.ecosystems {
+		var releases []release
+		for _, version := range pg.index.Versions(ecosystem, name) {
+			if info, ok := pg.LookupKey(NodeKey{Ecosystem: ecosystem, Name: name, Version: version}); ok {
+				released, err := parseTimestamp(info)
+				releases = append(releases, release{info: info, released: released, valid: err == nil})
+			}
+		}
+		sort.Slice(releases, func(i, j int) bool {
+			a, b := releases[i], releases[j]
+			switch {
+			case a.valid != b.valid:
+				return a.valid
+			case !a.released.Equal(b.released):
+				return a.released.Before(b.released)
+			case isNewerVersion(pg.index, b.info, a.info):
+				return true
+			case isNewerVersion(pg.index, a.info, b.info):
+				return false
+			default:
+				return a.info.Version < b.info.Version // The dialect considers them equal, as 1.0 and 1.0.0
+			}
+		})
+		for _, r := range releases {
+			result = append(result, r.info)
+		}
+	}
+	return result
+}
+
+// find returns the node with the given key, or an error wrapping ErrPackageNotFound when it is not in the graph.
+func (pg *PackageGraph) find(key NodeKey) (NodeInfo, error) {
+	info, ok := pg.LookupKey(key)
+	if !ok {
+		return NodeInfo{}, fmt.Errorf("%w: %s", ErrPackageNotFound, key)
+	}
+	return info, nil
+}
+
+// Dependencies returns the direct dependencies of the node with the given key, sorted by name and version, together
+// with the edges that lead to them. See GetDirectDependenciesNode.
+func (pg *PackageGraph) Dependencies(key NodeKey) ([]Dependency, error) {
+	if _, err := pg.find(key); err != nil {
+		return nil, err
+	}
+	return *GetDirectDependenciesNode(pg.directed, pg.nodes, pg.index, key), nil
+}
+
+// Dependents returns the nodes that depend directly on the node with the given key, sorted by name and version,
+// together with the edges that lead from them. See GetDirectDependentsNode.
+func (pg *PackageGraph) Dependents(key NodeKey) ([]Dependency, error) {
+	if _, err := pg.find(key); err != nil {
+		return nil, err
+	}
+	return *GetDirectDependentsNode(pg.directed, pg.nodes, pg.index, key), nil
+}
+
+// TransitiveDependencies returns the node with the given key and all of its dependencies. See
+// GetTransitiveDependenciesNode.
+func (pg *PackageGraph) TransitiveDependencies(key NodeKey) ([]NodeInfo, error) {
+	if _, err := pg.find(key); err != nil {
+		return nil, err
+	}
+	return *GetTransitiveDependenciesNode(pg.directed, pg.nodes, pg.index, key), nil
+}
+
+// TransitiveDependents returns the node with the given key and all the nodes that depend on it. See
+// GetTransitiveDependentsNode.
+func (pg *PackageGraph) TransitiveDependents(key NodeKey) ([]NodeInfo, error) {
+	if _, err := pg.find(key); err != nil {
+		return nil, err
+	}
+	return *GetTransitiveDependentsNode(pg.directed, pg.nodes, pg.index, key), nil
+}
+
+// TraverseDependencies returns the node with the given key and its dependencies within the limits of the options,
+// with the depth at which they were reached. See the TraverseDependencies function.
+func (pg *PackageGraph) TraverseDependencies(ctx context.Context, key NodeKey, options TraversalOptions) ([]ReachedNode, error) {
+	if _, err := pg.find(key); err != nil {
+		return nil, err
+	}
+	result, err := TraverseDependencies(ctx, pg.directed, pg.nodes, pg.index, key, options)
+	if err != nil {
+		return nil, err
+	}
+	return *result, nil
+}
+
+// TraverseDependents returns the node with the given key and the nodes that depend on it within the limits of the
+// options, with the depth at which they were reached. See the TraverseDependents function.
+func (pg *PackageGraph) TraverseDependents(ctx context.Context, key NodeKey, options TraversalOptions) ([]ReachedNode, error) {
+	if _, err := pg.find(key); err != nil {
+		return nil, err
+	}
+	result, err := TraverseDependents(ctx, pg.directed, pg.nodes, pg.index, key, options)
+	if err != nil {
+		return nil, err
+	}
+	return *result, nil
+}
+
+// LatestTransitiveDependencies returns the node with the given key and the latest version of every package it may
+// depend on. See GetLatestTransitiveDependenciesNode.
+func (pg *PackageGraph) LatestTransitiveDependencies(key NodeKey) ([]NodeInfo, error) {
+	if _, err := pg.find(key); err != nil {
+		return nil, err
+	}
+	return *GetLatestTransitiveDependenciesNode(pg.directed, pg.nodes, pg.index, key), nil
+}
+
+// ResolveAsOf resolves the dependencies of the node with the given key as they were resolved at time t. See the
+// ResolveAsOf function. The timestamp of the node itself must be valid, or a *TimestampParseError is returned.
+func (pg *PackageGraph) ResolveAsOf(key NodeKey, t time.Time) ([]NodeInfo, error) {
+	info, err := pg.find(key)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := parseTimestamp(info); err != nil && !info.External {
+		return nil, err
+	}
+	return *ResolveAsOf(pg.directed, pg.nodes, pg.index, key, t), nil
+}
+
+// WhyDepends returns the paths through which the node with the given key depends on the package with the given name.
+// See the WhyDepends function.
+func (pg *PackageGraph) WhyDepends(root NodeKey, target string, options WhyOptions) ([]DependencyPath, error) {
+	if _, err := pg.find(root); err != nil {
+		return nil, err
+	}
+	return *WhyDepends(pg.directed, pg.nodes, pg.index, root, target, options), nil
+}
+
+// Cycles returns the dependency cycles of the graph, grouped by the packages they are made of. See CyclicComponents
+// and ReportCycles.
+func (pg *PackageGraph) Cycles() *CycleReport {
+	return ReportCycles(pg.index, CyclicComponents(pg.directed, pg.nodes))
+}
+
+// InstallLayers orders the resolved versions, as returned by LatestTransitiveDependencies or ResolveAsOf, in the
+// layers in which they can be installed. See the InstallLayers function.
+func (pg *PackageGraph) InstallLayers(resolved []NodeInfo) []InstallLayer {
+	return InstallLayers(pg.directed, pg.nodes, resolved)
+}
+
+// DiffClosures compares the latest dependencies of the nodes with the given keys. See the DiffClosures function.
+func (pg *PackageGraph) DiffClosures(from, to NodeKey) (*ClosureDiff, error) {
+	for _, key := range []NodeKey{from, to} {
+		if _, err := pg.find(key); err != nil {
+			return nil, err
+		}
+	}
+	return DiffClosures(pg.directed, pg.nodes, pg.index, from, to), nil
+}
+
+// Filter selects part of a PackageGraph. It returns a view of the directed graph of the PackageGraph.
+type Filter func(pg *PackageGraph) (graph.Directed, error)
+
+// Filter returns the part of the graph that all the filters select, applied in order. The graph itself is not
+// modified.
+func (pg *PackageGraph) Filter(filters ...Filter) (*PackageGraph, error) {
+	result := pg
+	for _, filter := range filters {
+		view, err := filter(result)
+		if err != nil {
+			return nil, err
+		}
+		result = &PackageGraph{directed: view, nodes: pg.nodes, index: pg.index, ecosystems: pg.ecosystems}
+	}
+	return result, nil
+}
+
+// ReleasedBetween selects the packages released between beginTime and endTime. See FilterView.
+func ReleasedBetween(beginTime, endTime time.Time) Filter {
+	return func(pg *PackageGraph) (graph.Directed, error) {
+		return FilterView(pg.directed, pg.nodes, beginTime, endTime), nil
+	}
+}
+
+// LatestReleases selects the latest release of every package. See FilterLatestView.
+func LatestReleases() Filter {
+	return func(pg *PackageGraph) (graph.Directed, error) {
+		return FilterLatestView(pg.directed, pg.nodes, pg.index), nil
+	}
+}
+
+// OfKinds selects the dependencies of the given kinds. See FilterKindView.
+func OfKinds(kinds ...DependencyKind) Filter {
+	return func(pg *PackageGraph) (graph.Directed, error) {
+		return FilterKindView(pg.directed, kinds...), nil
+	}
+}
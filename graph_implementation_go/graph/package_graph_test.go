@@ -0,0 +1,106 @@
+package graph
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPackageGraph(t *testing.T) {
+	graph, index, nodeMap := createDependentsTestGraph()
+	pg := NewPackageGraph(graph, index, nodeMap)
+
+	t.Run("Looks up nodes", func(t *testing.T) {
+		if !reflect.DeepEqual(pg.Ecosystems(), []string{""}) {
+			t.Errorf("Expected a single unnamed ecosystem, got %v", pg.Ecosystems())
+		}
+		if len(pg.Nodes()) != len(nodeMap) {
+			t.Errorf("Expected %d nodes, got %d", len(nodeMap), len(pg.Nodes()))
+		}
+		if info, ok := pg.Lookup("B", "1.0.0"); !ok || info.Key() != testKey("B-1.0.0") {
+			t.Errorf("Expected to find B-1.0.0, got %v", info)
+		}
+		if _, ok := pg.Lookup("B", "2.0.0"); ok {
+			t.Errorf("Expected B-2.0.0 not to be found")
+		}
+		var versions []string
+		for _, info := range pg.Versions("A") {
+			versions = append(versions, info.Version)
+		}
+		if !reflect.DeepEqual(versions, []string{"1.0.0", "2.0.0"}) {
+			t.Errorf("Expected the versions of A in release order, got %v", versions)
+		}
+		dependents, err := pg.Dependents(testKey("A-1.0.0"))
+		if err != nil || len(dependents) != 1 || dependents[0].Key() != testKey("B-1.0.0") ||
+			dependents[0].Edge.Constraint != "1.0.0" {
+			t.Errorf("Expected B-1.0.0 to depend on A-1.0.0 through 1.0.0, got %v (%v)", dependents, err)
+		}
+	})
+
+	t.Run("Filters the graph", func(t *testing.T) {
+		beginTime, _ := time.Parse(time.RFC3339, "2021-01-01T00:00:00Z")
+		endTime, _ := time.Parse(time.RFC3339, "2021-12-31T00:00:00Z")
+		view, err := pg.Filter(ReleasedBetween(beginTime, endTime), OfKinds(Runtime))
+		if err != nil {
+			t.Fatalf("Filtering failed: %v", err)
+		}
+		if _, ok := view.LookupKey(testKey("C-1.0.0")); ok {
+			t.Errorf("Expected C-1.0.0 to be filtered out")
+		}
+		if _, ok := pg.LookupKey(testKey("C-1.0.0")); !ok {
+			t.Errorf("Expected C-1.0.0 to still be in the graph that was filtered")
+		}
+		if len(view.Versions("A")) != 2 || len(view.Nodes()) != 3 {
+			t.Errorf("Expected A, B and their versions to be kept, got %v", view.Nodes())
+		}
+		dependents, err := view.TransitiveDependents(testKey("A-1.0.0"))
+		if err != nil || len(dependents) != 2 {
+			t.Errorf("Expected A-1.0.0 and B-1.0.0, got %v (%v)", dependents, err)
+		}
+		if _, err := view.TransitiveDependencies(testKey("C-1.0.0")); !errors.Is(err, ErrPackageNotFound) {
+			t.Errorf("Expected ErrPackageNotFound for a filtered out package, got %v", err)
+		}
+	})
+}
+
+func TestPackageGraphConcurrentDependents(t *testing.T) {
+	graph, index, nodeMap := createDependentsTestGraph()
+	pg := NewPackageGraph(graph, index, nodeMap)
+
+	// The first queries of the dependents build the reverse index, which must be safe under the race detector
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if dependents, err := pg.TransitiveDependents(testKey("A-1.0.0")); err != nil || len(dependents) < 2 {
+				t.Errorf("Expected A-1.0.0 and its dependents, got %v (%v)", dependents, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPackageGraphVersionsOrder(t *testing.T) {
+	packagesInfo := []PackageInfo{{Name: "A", Versions: map[string]VersionInfo{
+		"1.0.0":  {Timestamp: "2021-01-01T09:00:00Z"},
+		"0.9.0":  {Timestamp: "2021-01-01T10:00:00+02:00"},
+		"1.10.0": {Timestamp: "2021-02-01T00:00:00Z"},
+		"1.9.0":  {Timestamp: "2021-02-01T00:00:00.000Z"},
+		"0.1.0":  {Timestamp: "unknown"},
+	}}}
+	graph := NewDirectedGraph()
+	index, nodeMap := CreateMaps(&packagesInfo, graph)
+	pg := NewPackageGraph(graph, index, nodeMap)
+
+	// The timestamps are compared as times, whatever their offset and precision
+	var versions []string
+	for _, info := range pg.Versions("A") {
+		versions = append(versions, info.Version)
+	}
+	if expected := []string{"0.9.0", "1.0.0", "1.9.0", "1.10.0", "0.1.0"}; !reflect.DeepEqual(versions, expected) {
+		t.Errorf("Expected the versions %v, got %v", expected, versions)
+	}
+}
@@ -0,0 +1,206 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// DefaultMaxPaths is the number of paths WhyDepends returns at most when all the paths are asked for and
+// WhyOptions.MaxPaths is not set. The number of simple paths grows exponentially with the size of the graph.
+const DefaultMaxPaths = 100
+
+// DependencyPath is a chain of dependencies from a root node to a version of another package. Edges[i] is the edge
+// from Nodes[i] to Nodes[i+1], so it holds the constraint that explains every hop.
+type DependencyPath struct {
+	Nodes []NodeInfo
+	Edges []DependencyEdge
+}
+
+// String returns the path as name@version --[constraint (kind)]--> name@version...
+func (path DependencyPath) String() string {
+	var b strings.Builder
+	for i, node := range path.Nodes {
+		if i > 0 {
+			fmt.Fprintf(&b, " --[%v]--> ", path.Edges[i-1])
+		}
+		b.WriteString(node.Key().String())
+	}
+	return b.String()
+}
+
+// Len returns the number of edges of the path.
+func (path DependencyPath) Len() int {
+	return len(path.Edges)
+}
+
+// WhyOptions selects which paths WhyDepends returns.
+type WhyOptions struct {
+	// All selects all the simple paths instead of a single shortest one
+	All bool
+	// MaxPaths is the number of paths returned at most when All is set. DefaultMaxPaths is used when it is 0
+	MaxPaths int
+	// MaxLength is the number of edges of the longest path returned. Paths of any length are returned when it is 0
+	MaxLength int
+}
+
+// WhyDepends explains why the specified node depends on the package with the given name, which belongs to the same
+// ecosystem as the node. It returns a shortest path from the node to any version of the package or, when options.All
+// is set, the simple paths to its versions up to the bounds of the options. Paths end at the first version of the
+// package they reach, and are returned in breadth-first order for the shortest one and in depth-first order, following
+// the dependencies sorted by name and version, otherwise. The result is empty when the node does not depend on the
+// package, and is a single path without edges when the node is a version of the package itself.
+func WhyDepends(g graph.Directed, nodeMap map[int64]NodeInfo, index *NodeIndex, root NodeKey, target string, options WhyOptions) *[]DependencyPath {
+	result := make([]DependencyPath, 0)
+	rootId, ok := findNode(index, nodeMap, root)
+	if !ok || g.Node(rootId) == nil {
+		return &result // This function is a no-op if we don't have a correct key or the node was filtered out
+	}
+	isTarget := func(id int64) bool {
+		info := nodeMap[id]
+		return info.Name == target && info.Ecosystem == root.Ecosystem
+	}
+	if isTarget(rootId) {
+		return &[]DependencyPath{{Nodes: []NodeInfo{nodeMap[rootId]}}}
+	}
+
+	if !options.All {
+		if path, ok := shortestDependencyPath(g, nodeMap, rootId, isTarget, options.MaxLength); ok {
+			result = append(result, path)
+		}
+		return &result
+	}
+
+	maxPaths := options.MaxPaths
+	if maxPaths <= 0 {
+		maxPaths = DefaultMaxPaths
+	}
+	leadsToTarget := nodesLeadingTo(g, rootId, isTarget)
+	path := DependencyPath{Nodes: []NodeInfo{nodeMap[rootId]}}
+	onPath := map[int64]struct{}{rootId: {}}
+
+	// The walk only enters the nodes from which a version of the target can be reached, so it does not explore the
+	// parts of the graph that cannot lead to a path
+	var walk func(id int64)
+	walk = func(id int64) {
+		if options.MaxLength > 0 && path.Len() == options.MaxLength {
+			return
+		}
+		for _, dependency := range directDependencies(g, nodeMap, id) {
+			if len(result) == maxPaths {
+				return
+			}
+			next := dependency.id
+			if _, ok := onPath[next]; ok {
+				continue
+			}
+			if _, ok := leadsToTarget[next]; !ok {
+				continue
+			}
+			path.Nodes = append(path.Nodes, dependency.NodeInfo)
+			path.Edges = append(path.Edges, dependency.Edge)
+			if isTarget(next) {
+				result = append(result, DependencyPath{
+					Nodes: append([]NodeInfo(nil), path.Nodes...),
+					Edges: append([]DependencyEdge(nil), path.Edges...),
+				})
+			} else {
+				onPath[next] = struct{}{}
+				walk(next)
+				delete(onPath, next)
+			}
+			path.Nodes = path.Nodes[:len(path.Nodes)-1]
+			path.Edges = path.Edges[:len(path.Edges)-1]
+		}
+	}
+	walk(rootId)
+	return &result
+}
+
+// shortestDependencyPath returns the shortest path from the root to a target node with a breadth-first search, whose
+// dependencies are visited by name and version so the path is always the same. The bool is false when no target node
+// can be reached in at most maxLength edges, or at all when maxLength is 0.
+func shortestDependencyPath(g graph.Directed, nodeMap map[int64]NodeInfo, rootId int64, isTarget func(int64) bool, maxLength int) (DependencyPath, bool) {
+	type step struct {
+		from int64
+		edge DependencyEdge
+	}
+	reachedFrom := map[int64]step{rootId: {from: -1}}
+	level := []int64{rootId}
+	for length := 1; len(level) > 0 && (maxLength <= 0 || length <= maxLength); length++ {
+		var nextLevel []int64
+		for _, id := range level {
+			for _, dependency := range directDependencies(g, nodeMap, id) {
+				next := dependency.id
+				if _, ok := reachedFrom[next]; ok {
+					continue
+				}
+				reachedFrom[next] = step{from: id, edge: dependency.Edge}
+				if !isTarget(next) {
+					nextLevel = append(nextLevel, next)
+					continue
+				}
+
+				// Walk back to the root, then reverse the path
+				var path DependencyPath
+				for current := next; current != rootId; current = reachedFrom[current].from {
+					path.Nodes = append(path.Nodes, nodeMap[current])
+					path.Edges = append(path.Edges, reachedFrom[current].edge)
+				}
+				path.Nodes = append(path.Nodes, nodeMap[rootId])
+				for i, j := 0, len(path.Nodes)-1; i < j; i, j = i+1, j-1 {
+					path.Nodes[i], path.Nodes[j] = path.Nodes[j], path.Nodes[i]
+				}
+				for i, j := 0, len(path.Edges)-1; i < j; i, j = i+1, j-1 {
+					path.Edges[i], path.Edges[j] = path.Edges[j], path.Edges[i]
+				}
+				return path, true
+			}
+		}
+		level = nextLevel
+	}
+	return DependencyPath{}, false
+}
+
+// nodesLeadingTo returns the nodes reachable from the root from which a target node can be reached, target nodes
+// included. The edges followed from the root are recorded on the way, so the graph does not need a reverse index.
+func nodesLeadingTo(g graph.Directed, rootId int64, isTarget func(int64) bool) map[int64]struct{} {
+	dependents := make(map[int64][]int64)
+	visited := map[int64]struct{}{rootId: {}}
+	var targets []int64
+	queue := []int64{rootId}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for dependencies := g.From(id); dependencies.Next(); {
+			next := dependencies.Node().ID()
+			dependents[next] = append(dependents[next], id)
+			if _, ok := visited[next]; ok {
+				continue
+			}
+			visited[next] = struct{}{}
+			if isTarget(next) {
+				targets = append(targets, next) // Paths end at the targets, so their dependencies do not matter
+			} else {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	result := make(map[int64]struct{}, len(targets))
+	for _, id := range targets {
+		result[id] = struct{}{}
+	}
+	for len(targets) > 0 {
+		id := targets[0]
+		targets = targets[1:]
+		for _, dependent := range dependents[id] {
+			if _, ok := result[dependent]; !ok {
+				result[dependent] = struct{}{}
+				targets = append(targets, dependent)
+			}
+		}
+	}
+	return result
+}
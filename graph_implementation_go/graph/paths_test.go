@@ -0,0 +1,86 @@
+package graph
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func createPathsTestGraph() *PackageGraph {
+	packagesInfo := []PackageInfo{
+		{Name: "R", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2021-01-01T00:00:00Z", Dependencies: map[string]string{"X": "1.0.0", "Y": "1.0.0"}},
+		}},
+		{Name: "X", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2021-01-01T00:00:00Z", Dependencies: map[string]string{"T": ">=1.0.0"}},
+		}},
+		{Name: "Y", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2021-01-01T00:00:00Z", Dependencies: map[string]string{"X": "1.0.0", "Z": "1.0.0"}},
+		}},
+		{Name: "Z", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2021-01-01T00:00:00Z", Dependencies: map[string]string{"Y": "1.0.0"}},
+		}},
+		{Name: "T", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2021-01-01T00:00:00Z", Dependencies: map[string]string{}},
+			"2.0.0": {Timestamp: "2021-02-01T00:00:00Z", Dependencies: map[string]string{}},
+		}},
+		{Name: "U", Versions: map[string]VersionInfo{
+			"1.0.0": {Timestamp: "2021-01-01T00:00:00Z", Dependencies: map[string]string{}},
+		}},
+	}
+	graph := NewDirectedGraph()
+	index, nodeMap := CreateMaps(&packagesInfo, graph)
+	CreateEdges(context.Background(), graph, &packagesInfo, index, nodeMap, SemverDialect)
+	return NewPackageGraph(graph, index, nodeMap)
+}
+
+func pathStrings(paths []DependencyPath) []string {
+	result := make([]string, len(paths))
+	for i, path := range paths {
+		result[i] = path.String()
+	}
+	return result
+}
+
+func TestWhyDepends(t *testing.T) {
+	pg := createPathsTestGraph()
+
+	for _, test := range []struct {
+		name     string
+		target   string
+		options  WhyOptions
+		expected []string
+	}{
+		{"Shortest path", "T", WhyOptions{}, []string{
+			"R@1.0.0 --[1.0.0 (runtime)]--> X@1.0.0 --[>=1.0.0 (runtime)]--> T@1.0.0",
+		}},
+		{"All paths", "T", WhyOptions{All: true}, []string{
+			"R@1.0.0 --[1.0.0 (runtime)]--> X@1.0.0 --[>=1.0.0 (runtime)]--> T@1.0.0",
+			"R@1.0.0 --[1.0.0 (runtime)]--> X@1.0.0 --[>=1.0.0 (runtime)]--> T@2.0.0",
+			"R@1.0.0 --[1.0.0 (runtime)]--> Y@1.0.0 --[1.0.0 (runtime)]--> X@1.0.0 --[>=1.0.0 (runtime)]--> T@1.0.0",
+			"R@1.0.0 --[1.0.0 (runtime)]--> Y@1.0.0 --[1.0.0 (runtime)]--> X@1.0.0 --[>=1.0.0 (runtime)]--> T@2.0.0",
+		}},
+		{"Bounded number of paths", "T", WhyOptions{All: true, MaxPaths: 1}, []string{
+			"R@1.0.0 --[1.0.0 (runtime)]--> X@1.0.0 --[>=1.0.0 (runtime)]--> T@1.0.0",
+		}},
+		{"Bounded length", "X", WhyOptions{All: true, MaxLength: 1}, []string{
+			"R@1.0.0 --[1.0.0 (runtime)]--> X@1.0.0",
+		}},
+		{"Shortest path too long", "T", WhyOptions{MaxLength: 1}, []string{}},
+		{"Through a cycle", "Z", WhyOptions{All: true}, []string{
+			"R@1.0.0 --[1.0.0 (runtime)]--> Y@1.0.0 --[1.0.0 (runtime)]--> Z@1.0.0",
+		}},
+		{"Not a dependency", "U", WhyOptions{All: true}, []string{}},
+		{"Root is the target", "R", WhyOptions{}, []string{"R@1.0.0"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			paths, err := pg.WhyDepends(testKey("R-1.0.0"), test.target, test.options)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if actual := pathStrings(paths); !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
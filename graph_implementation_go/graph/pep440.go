@@ -0,0 +1,447 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// This file implements the versions and the version specifiers of PEP 440, which are used by PyPI. The behaviour
+// follows the reference implementation in the packaging library. See https://peps.python.org/pep-0440/.
+
+// pep440VersionPattern is the pattern from Appendix B of PEP 440. It accepts all the alternative spellings, which
+// are normalized when the version is parsed.
+var pep440VersionPattern = regexp.MustCompile(`(?i)^\s*v?` +
+	`(?:(?P<epoch>[0-9]+)!)?` +
+	`(?P<release>[0-9]+(?:\.[0-9]+)*)` +
+	`(?P<pre>[-_.]?(?P<pre_l>alpha|beta|preview|pre|rc|a|b|c)[-_.]?(?P<pre_n>[0-9]+)?)?` +
+	`(?P<post>(?:-(?P<post_n1>[0-9]+))|(?:[-_.]?(?P<post_l>post|rev|r)[-_.]?(?P<post_n2>[0-9]+)?))?` +
+	`(?P<dev>[-_.]?(?P<dev_l>dev)[-_.]?(?P<dev_n>[0-9]+)?)?` +
+	`(?:\+(?P<local>[a-z0-9]+(?:[-_.][a-z0-9]+)*))?` +
+	`\s*$`)
+
+// ErrInvalidPEP440 is returned when a version or a specifier does not follow PEP 440.
+var ErrInvalidPEP440 = errors.New("invalid PEP 440 version")
+
+// PEP440Version is a parsed PEP 440 version, such as 1!2.0.1rc1.post2.dev3+local.7.
+type PEP440Version struct {
+	original string
+	epoch    int64
+	release  []int64
+	// preLetter is "a", "b" or "rc", and empty when the version is not a pre-release
+	preLetter string
+	preNumber int64
+	hasPost   bool
+	post      int64
+	hasDev    bool
+	dev       int64
+	local     []string
+}
+
+// ParsePEP440Version parses a PEP 440 version.
+func ParsePEP440Version(version string) (*PEP440Version, error) {
+	match := pep440VersionPattern.FindStringSubmatch(version)
+	if match == nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidPEP440, version)
+	}
+	group := func(name string) string {
+		return match[pep440VersionPattern.SubexpIndex(name)]
+	}
+
+	v := &PEP440Version{original: version}
+	var err error
+	number := func(s string) int64 {
+		if s == "" || err != nil {
+			return 0
+		}
+		var n int64
+		n, err = strconv.ParseInt(s, 10, 64)
+		return n
+	}
+
+	v.epoch = number(group("epoch"))
+	for _, part := range strings.Split(group("release"), ".") {
+		v.release = append(v.release, number(part))
+	}
+	if group("pre") != "" {
+		switch strings.ToLower(group("pre_l")) {
+		case "a", "alpha":
+			v.preLetter = "a"
+		case "b", "beta":
+			v.preLetter = "b"
+		default: // c, rc, pre and preview
+			v.preLetter = "rc"
+		}
+		v.preNumber = number(group("pre_n"))
+	}
+	if group("post") != "" {
+		v.hasPost = true
+		v.post = number(group("post_n1") + group("post_n2"))
+	}
+	if group("dev") != "" {
+		v.hasDev = true
+		v.dev = number(group("dev_n"))
+	}
+	if local := group("local"); local != "" {
+		v.local = strings.FieldsFunc(strings.ToLower(local), func(r rune) bool {
+			return r == '-' || r == '_' || r == '.'
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q (%v)", ErrInvalidPEP440, version, err)
+	}
+	return v, nil
+}
+
+// IsPrerelease returns whether the version is a pre-release or a development release.
+func (v *PEP440Version) IsPrerelease() bool {
+	return v.preLetter != "" || v.hasDev
+}
+
+// IsPostrelease returns whether the version is a post-release.
+func (v *PEP440Version) IsPostrelease() bool {
+	return v.hasPost
+}
+
+// String returns the normalized form of the version.
+func (v *PEP440Version) String() string {
+	var b strings.Builder
+	if v.epoch != 0 {
+		fmt.Fprintf(&b, "%d!", v.epoch)
+	}
+	b.WriteString(v.baseRelease())
+	if v.preLetter != "" {
+		fmt.Fprintf(&b, "%s%d", v.preLetter, v.preNumber)
+	}
+	if v.hasPost {
+		fmt.Fprintf(&b, ".post%d", v.post)
+	}
+	if v.hasDev {
+		fmt.Fprintf(&b, ".dev%d", v.dev)
+	}
+	if len(v.local) > 0 {
+		b.WriteString("+" + strings.Join(v.local, "."))
+	}
+	return b.String()
+}
+
+func (v *PEP440Version) baseRelease() string {
+	parts := make([]string, len(v.release))
+	for i, n := range v.release {
+		parts[i] = strconv.FormatInt(n, 10)
+	}
+	return strings.Join(parts, ".")
+}
+
+// public returns the version without its local label.
+func (v *PEP440Version) public() *PEP440Version {
+	public := *v
+	public.local = nil
+	return &public
+}
+
+// base returns the version with only its epoch and its release segment.
+func (v *PEP440Version) base() *PEP440Version {
+	return &PEP440Version{epoch: v.epoch, release: v.release}
+}
+
+// pep440Key is one element of the comparison key of a version. Missing parts of a version sort either before or after
+// all the values, depending on the part.
+type pep440Key struct {
+	infinity int // -1 sorts before all the values, 1 after all of them
+	value    int64
+}
+
+func (k pep440Key) compare(other pep440Key) int {
+	if k.infinity != other.infinity {
+		return compareInts(int64(k.infinity), int64(other.infinity))
+	}
+	return compareInts(k.value, other.value)
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+var pep440PreLetterRanks = map[string]int64{"a": 0, "b": 1, "rc": 2}
+
+// Compare returns -1, 0 or 1 when v is respectively lower than, equal to or greater than other.
+func (v *PEP440Version) Compare(other *PEP440Version) int {
+	if c := compareInts(v.epoch, other.epoch); c != 0 {
+		return c
+	}
+	if c := compareReleases(v.release, other.release); c != 0 {
+		return c
+	}
+	for _, keys := range [][2]pep440Key{
+		{v.preLetterKey(), other.preLetterKey()},
+		{v.preNumberKey(), other.preNumberKey()},
+		{v.postKey(), other.postKey()},
+		{v.devKey(), other.devKey()},
+	} {
+		if c := keys[0].compare(keys[1]); c != 0 {
+			return c
+		}
+	}
+	return compareLocals(v.local, other.local)
+}
+
+// compareReleases compares two release segments, ignoring their trailing zeros so that 1.0 equals 1.
+func compareReleases(a, b []int64) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int64
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if c := compareInts(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func (v *PEP440Version) preLetterKey() pep440Key {
+	switch {
+	case v.preLetter == "" && !v.hasPost && v.hasDev: // 1.0.dev0 sorts before 1.0a0
+		return pep440Key{infinity: -1}
+	case v.preLetter == "":
+		return pep440Key{infinity: 1}
+	default:
+		return pep440Key{value: pep440PreLetterRanks[v.preLetter]}
+	}
+}
+
+func (v *PEP440Version) preNumberKey() pep440Key {
+	return pep440Key{value: v.preNumber}
+}
+
+func (v *PEP440Version) postKey() pep440Key {
+	if !v.hasPost {
+		return pep440Key{infinity: -1}
+	}
+	return pep440Key{value: v.post}
+}
+
+func (v *PEP440Version) devKey() pep440Key {
+	if !v.hasDev {
+		return pep440Key{infinity: 1}
+	}
+	return pep440Key{value: v.dev}
+}
+
+// compareLocals compares two local labels. A version without a label sorts before the same version with one, numeric
+// parts sort after alphanumeric ones, and a shorter label sorts before a longer one it is a prefix of.
+func compareLocals(a, b []string) int {
+	if len(a) == 0 || len(b) == 0 {
+		return compareInts(int64(len(a)), int64(len(b)))
+	}
+	for i := 0; i < len(a) && i < len(b); i++ {
+		x, xErr := strconv.ParseInt(a[i], 10, 64)
+		y, yErr := strconv.ParseInt(b[i], 10, 64)
+		var c int
+		switch {
+		case xErr == nil && yErr == nil:
+			c = compareInts(x, y)
+		case xErr == nil:
+			c = 1
+		case yErr == nil:
+			c = -1
+		default:
+			c = strings.Compare(a[i], b[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return compareInts(int64(len(a)), int64(len(b)))
+}
+
+// pep440Specifier is a single clause of a specifier set, such as >=1.0 or ==1.2.*.
+type pep440Specifier struct {
+	operator string
+	// version is the raw version of the clause, without the wildcard suffix
+	version string
+	// parsed is nil for the arbitrary equality operator (===), which compares strings
+	parsed   *PEP440Version
+	wildcard bool
+}
+
+var pep440SpecifierPattern = regexp.MustCompile(`^\s*(~=|===|==|!=|<=|>=|<|>)\s*(\S+?)\s*$`)
+
+func parsePEP440Specifier(s string) (pep440Specifier, error) {
+	match := pep440SpecifierPattern.FindStringSubmatch(s)
+	if match == nil {
+		return pep440Specifier{}, fmt.Errorf("%w: invalid specifier %q", ErrInvalidPEP440, s)
+	}
+	spec := pep440Specifier{operator: match[1], version: match[2]}
+	if spec.operator == "===" {
+		return spec, nil
+	}
+
+	if strings.HasSuffix(spec.version, ".*") {
+		if spec.operator != "==" && spec.operator != "!=" {
+			return spec, fmt.Errorf("%w: wildcards are only allowed with == and != in %q", ErrInvalidPEP440, s)
+		}
+		spec.wildcard = true
+		spec.version = strings.TrimSuffix(spec.version, ".*")
+	}
+
+	parsed, err := ParsePEP440Version(spec.version)
+	if err != nil {
+		return spec, err
+	}
+	if spec.wildcard && (parsed.preLetter != "" || parsed.hasPost || parsed.hasDev || len(parsed.local) > 0) {
+		return spec, fmt.Errorf("%w: wildcards are only allowed after a release segment in %q", ErrInvalidPEP440, s)
+	}
+	if len(parsed.local) > 0 && spec.operator != "==" && spec.operator != "!=" {
+		return spec, fmt.Errorf("%w: local versions are only allowed with == and != in %q", ErrInvalidPEP440, s)
+	}
+	if spec.operator == "~=" && len(parsed.release) < 2 {
+		return spec, fmt.Errorf("%w: ~= needs at least two release segments in %q", ErrInvalidPEP440, s)
+	}
+	spec.parsed = parsed
+	return spec, nil
+}
+
+// allowsPrereleases returns whether the clause explicitly mentions a pre-release, which makes the whole specifier set
+// accept pre-releases.
+func (s pep440Specifier) allowsPrereleases() bool {
+	return s.operator != "!=" && s.parsed != nil && s.parsed.IsPrerelease()
+}
+
+func (s pep440Specifier) contains(v *PEP440Version) bool {
+	switch s.operator {
+	case "===":
+		return strings.EqualFold(v.original, s.version)
+	case "==":
+		return s.equal(v)
+	case "!=":
+		return !s.equal(v)
+	case "~=":
+		// ~=2.2.1 is >=2.2.1, ==2.2.*
+		prefix := pep440Specifier{operator: "==", parsed: &PEP440Version{
+			epoch:   s.parsed.epoch,
+			release: s.parsed.release[:len(s.parsed.release)-1],
+		}, wildcard: true}
+		return v.public().Compare(s.parsed) >= 0 && prefix.equal(v)
+	case "<=":
+		return v.public().Compare(s.parsed) <= 0
+	case ">=":
+		return v.public().Compare(s.parsed) >= 0
+	case "<":
+		// <3.1 does not match the pre-releases of 3.1, unless the specifier is itself a pre-release
+		if v.Compare(s.parsed) >= 0 {
+			return false
+		}
+		return s.parsed.IsPrerelease() || !v.IsPrerelease() || v.base().Compare(s.parsed.base()) != 0
+	case ">":
+		// >3.1 does not match the post-releases and the local versions of 3.1
+		if v.Compare(s.parsed) <= 0 {
+			return false
+		}
+		if !s.parsed.IsPostrelease() && v.IsPostrelease() && v.base().Compare(s.parsed.base()) == 0 {
+			return false
+		}
+		return len(v.local) == 0 || v.base().Compare(s.parsed.base()) != 0
+	default:
+		return false
+	}
+}
+
+func (s pep440Specifier) equal(v *PEP440Version) bool {
+	if !s.wildcard {
+		if len(s.parsed.local) == 0 {
+			v = v.public()
+		}
+		return v.Compare(s.parsed) == 0
+	}
+	// ==1.2.* matches every version whose release segment starts with 1.2, padding it with zeros when it is shorter
+	if v.epoch != s.parsed.epoch {
+		return false
+	}
+	for i, n := range s.parsed.release {
+		var m int64
+		if i < len(v.release) {
+			m = v.release[i]
+		}
+		if m != n {
+			return false
+		}
+	}
+	return true
+}
+
+func (s pep440Specifier) String() string {
+	version := s.version
+	if s.parsed != nil {
+		version = s.parsed.String()
+	}
+	if s.wildcard {
+		version += ".*"
+	}
+	return s.operator + version
+}
+
+// PEP440Specifiers is a parsed PEP 440 specifier set, such as ">=1.0, !=1.3.*, <2.0". A version must match all the
+// clauses. An empty set matches every version.
+type PEP440Specifiers struct {
+	specifiers  []pep440Specifier
+	prereleases bool
+}
+
+// ParsePEP440Specifiers parses a comma separated PEP 440 specifier set. The set may be surrounded by parentheses, as
+// in the Requires-Dist metadata.
+func ParsePEP440Specifiers(s string) (*PEP440Specifiers, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	result := &PEP440Specifiers{}
+	if s == "" {
+		return result, nil
+	}
+	for _, clause := range strings.Split(s, ",") {
+		spec, err := parsePEP440Specifier(clause)
+		if err != nil {
+			return nil, err
+		}
+		result.specifiers = append(result.specifiers, spec)
+		result.prereleases = result.prereleases || spec.allowsPrereleases()
+	}
+	return result, nil
+}
+
+// Check returns whether the version matches all the clauses of the set. Pre-releases only match when one of the
+// clauses explicitly mentions a pre-release, as pip does by default.
+func (s *PEP440Specifiers) Check(v *PEP440Version) bool {
+	if v.IsPrerelease() && !s.prereleases {
+		return false
+	}
+	for _, spec := range s.specifiers {
+		if !spec.contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the normalized form of the set.
+func (s *PEP440Specifiers) String() string {
+	parts := make([]string, len(s.specifiers))
+	for i, spec := range s.specifiers {
+		parts[i] = spec.String()
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,156 @@
+package graph
+
+import (
+	"errors"
+	"testing"
+)
+
+// The versions below come from the ordering examples of PEP 440 and from the tests of the packaging library. The list
+// is in strictly ascending order.
+var pep440Versions = []string{
+	"1.0.dev456", "1.0a1", "1.0a2.dev456", "1.0a12.dev456", "1.0a12", "1.0b1.dev456", "1.0b2", "1.0b2.post345.dev456",
+	"1.0b2.post345", "1.0rc1.dev456", "1.0rc1", "1.0", "1.0+abc.5", "1.0+abc.7", "1.0+5", "1.0.post456.dev34",
+	"1.0.post456", "1.0.15", "1.1.dev1", "1.1", "1!0.1",
+}
+
+func TestPEP440VersionOrder(t *testing.T) {
+	for i := 0; i < len(pep440Versions); i++ {
+		for j := i + 1; j < len(pep440Versions); j++ {
+			low, err := ParsePEP440Version(pep440Versions[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			high, err := ParsePEP440Version(pep440Versions[j])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if low.Compare(high) >= 0 || high.Compare(low) <= 0 {
+				t.Errorf("Expected %s < %s", pep440Versions[i], pep440Versions[j])
+			}
+		}
+	}
+}
+
+func TestPEP440VersionNormalization(t *testing.T) {
+	tests := map[string]string{
+		"1.0":              "1.0",
+		"v1.0":             "1.0",
+		"1.0-alpha1":       "1.0a1",
+		"1.0.BETA.2":       "1.0b2",
+		"1.0c1":            "1.0rc1",
+		"1.0preview3":      "1.0rc3",
+		"1.0a":             "1.0a0",
+		"1.0-1":            "1.0.post1",
+		"1.0.rev4":         "1.0.post4",
+		"1.0-r":            "1.0.post0",
+		"1.0dev":           "1.0.dev0",
+		"0!1.0":            "1.0",
+		"2!1.0":            "2!1.0",
+		"1.0+Ubuntu-1":     "1.0+ubuntu.1",
+		" 1.0.post2.dev3 ": "1.0.post2.dev3",
+	}
+	for version, expected := range tests {
+		v, err := ParsePEP440Version(version)
+		if err != nil {
+			t.Errorf("Expected %q to be valid, got %v", version, err)
+			continue
+		}
+		if v.String() != expected {
+			t.Errorf("Expected %q to normalize to %q, got %q", version, expected, v.String())
+		}
+	}
+}
+
+func TestPEP440VersionsEqual(t *testing.T) {
+	for _, test := range [][2]string{{"1", "1.0.0"}, {"1.0a1", "1.0.alpha.1"}, {"1.0-1", "1.0.post1"}, {"0!1", "1"}} {
+		a, _ := ParsePEP440Version(test[0])
+		b, _ := ParsePEP440Version(test[1])
+		if a.Compare(b) != 0 {
+			t.Errorf("Expected %s = %s", test[0], test[1])
+		}
+	}
+}
+
+func TestPEP440VersionInvalid(t *testing.T) {
+	for _, version := range []string{"", "1.0.x", "french toast", "1.0+", "1.0+a..b", "1.0beta1beta2"} {
+		if _, err := ParsePEP440Version(version); !errors.Is(err, ErrInvalidPEP440) {
+			t.Errorf("Expected %q to be invalid, got %v", version, err)
+		}
+	}
+}
+
+func TestPEP440Specifiers(t *testing.T) {
+	tests := []struct {
+		spec       string
+		matches    []string
+		notMatches []string
+	}{
+		{"", []string{"0.1", "1.0", "1.0.post1"}, []string{"1.0a1", "1.0.dev1"}},
+		{">=1.0", []string{"1.0", "1.0.post1", "2.0", "1.0+local"}, []string{"0.9", "2.0rc1"}},
+		{">=1.0rc1", []string{"1.0rc1", "1.0", "2.0b1"}, []string{"1.0b1"}},
+		{"<=1.0", []string{"0.9", "1.0", "1.0+local"}, []string{"1.0.post1", "1.1"}},
+		{"<2.0", []string{"1.9", "1.99.post1"}, []string{"2.0", "2.0a1", "2.0.dev1"}},
+		{"<2.0rc1", []string{"1.9", "2.0a1", "2.0b3"}, []string{"2.0rc1", "2.0"}},
+		{">1.0", []string{"1.1", "1.0.1"}, []string{"1.0", "1.0.post1", "1.0+local"}},
+		{">1.0.post1", []string{"1.0.post2", "1.1"}, []string{"1.0.post1"}},
+		{"==1.0", []string{"1.0", "1.0.0", "1.0+local"}, []string{"1.0.post1", "1.0.1"}},
+		{"==1.0+local", []string{"1.0+local"}, []string{"1.0", "1.0+other"}},
+		{"==1.2.*", []string{"1.2", "1.2.0", "1.2.3", "1.2.3.post1"}, []string{"1.3", "1.20", "1.2.3a1"}},
+		{"==1.*", []string{"1", "1.9"}, []string{"2.0"}},
+		{"!=1.5.*", []string{"1.4", "1.6"}, []string{"1.5", "1.5.2"}},
+		{"!=1.0", []string{"0.9", "1.0.post1"}, []string{"1.0", "1.0+local"}},
+		{"~=2.2", []string{"2.2", "2.3", "2.9.1"}, []string{"2.1", "3.0"}},
+		{"~=1.4.5", []string{"1.4.5", "1.4.9"}, []string{"1.5.0", "1.4.4"}},
+		{"~=2.2.post3", []string{"2.2.post3", "2.3"}, []string{"2.2", "3.0"}},
+		{"===1.0.0", []string{"1.0.0"}, []string{"1.0", "1.0.0+local"}},
+		{">=1.0, <2.0, !=1.5", []string{"1.0", "1.4", "1.9.9"}, []string{"1.5", "2.0", "0.9"}},
+		{"(>=1.0,<2)", []string{"1.5"}, []string{"2.0"}},
+	}
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			specifiers, err := ParsePEP440Specifiers(test.spec)
+			if err != nil {
+				t.Fatalf("Expected %q to be valid, got %v", test.spec, err)
+			}
+			for _, v := range test.matches {
+				version, err := ParsePEP440Version(v)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !specifiers.Check(version) {
+					t.Errorf("Expected %s to match %q", v, test.spec)
+				}
+			}
+			for _, v := range test.notMatches {
+				version, err := ParsePEP440Version(v)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if specifiers.Check(version) {
+					t.Errorf("Expected %s not to match %q", v, test.spec)
+				}
+			}
+		})
+	}
+}
+
+func TestPEP440SpecifiersInvalid(t *testing.T) {
+	for _, spec := range []string{"1.0", "=>1.0", ">=1.*", "~=1", "~=1.0.*", ">=1.0+local", "==1.0a1.*", ">=1.0,", "==x"} {
+		if _, err := ParsePEP440Specifiers(spec); !errors.Is(err, ErrInvalidPEP440) {
+			t.Errorf("Expected %q to be invalid, got %v", spec, err)
+		}
+	}
+}
+
+func TestDialectByName(t *testing.T) {
+	for name, expected := range map[string]Dialect{
+		"semver": SemverDialect, "Maven": MavenDialect, "pep440": PEP440Dialect, "pypi": PEP440Dialect,
+	} {
+		if d, err := DialectByName(name); err != nil || d != expected {
+			t.Errorf("Expected %q to be the %s dialect, got %v (%v)", name, expected.Name(), d, err)
+		}
+	}
+	if _, err := DialectByName("cargo"); err == nil {
+		t.Error("Expected an error for an unknown dialect")
+	}
+}
@@ -0,0 +1,31 @@
+package graph
+
+// The stages of the long operations, as passed to a ProgressReporter.
+const (
+	StageReading     = "reading packages"
+	StageEdges       = "creating edges"
+	StagePageRank    = "pagerank"
+	StageBetweenness = "betweenness"
+)
+
+// ProgressReporter is told how far the long operations, such as CreateGraph, PageRank and Betweenness, are. It is
+// never called concurrently by the same operation.
+type ProgressReporter interface {
+	// Progress is called with the stage of the operation, the amount of work done and the total amount of work, which
+	// is 0 when it is not known in advance.
+	Progress(stage string, done, total int)
+}
+
+// ProgressFunc is a function used as a ProgressReporter.
+type ProgressFunc func(stage string, done, total int)
+
+func (f ProgressFunc) Progress(stage string, done, total int) {
+	f(stage, done, total)
+}
+
+// reportProgress passes the progress to progress, which may be nil.
+func reportProgress(progress ProgressReporter, stage string, done, total int) {
+	if progress != nil {
+		progress.Progress(stage, done, total)
+	}
+}
@@ -0,0 +1,177 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// reportTopOffenders is the number of packages listed in the top offenders of an ecosystem
+	reportTopOffenders = 10
+	// reportSamplesPerIssue is the number of raw strings kept as samples for every kind of issue
+	reportSamplesPerIssue = 5
+)
+
+// The kinds of issues found while building the graph.
+const (
+	IssueUnparseableVersion    = "unparseable version"
+	IssueUnparseableConstraint = "unparseable constraint"
+	IssueUnparseableTimestamp  = "unparseable timestamp"
+	IssueUnresolvedDependency  = "unresolved dependency"
+	IssueMalformedRecord       = "malformed record"
+)
+
+var reportIssues = []string{
+	IssueUnparseableVersion, IssueUnparseableConstraint, IssueUnparseableTimestamp, IssueUnresolvedDependency,
+	IssueMalformedRecord,
+}
+
+// BuildReport describes what was left out while the graph was built: the packages that could not be decoded, the
+// versions, constraints and timestamps that could not be parsed, and the dependencies that did not resolve to any
+// version of the input. A graph can be built from several ecosystems, each read with its own dialect, so Ecosystems
+// holds a report for every ecosystem, keyed by its name. The ecosystems of CreateEdges are named after their dialect.
+type BuildReport struct {
+	Ecosystems map[string]*EcosystemReport `json:"ecosystems"`
+}
+
+// EcosystemReport is the part of a BuildReport about a single ecosystem.
+type EcosystemReport struct {
+	Packages     int `json:"packages"`
+	Versions     int `json:"versions"`
+	Dependencies int `json:"dependencies"`
+	Edges        int `json:"edges"`
+
+	UnparseableVersions    int `json:"unparseableVersions"`
+	UnparseableConstraints int `json:"unparseableConstraints"`
+	UnparseableTimestamps  int `json:"unparseableTimestamps"`
+	// UnresolvedDependencies counts the dependencies whose constraint was valid but did not select any version, most
+	// often because the dependency is not part of the input
+	UnresolvedDependencies int `json:"unresolvedDependencies"`
+	// MalformedRecords counts the packages of the input that could not be decoded and were left out of the graph
+	MalformedRecords int `json:"malformedRecords"`
+
+	// TopOffenders lists the packages with the most unparseable versions, constraints and timestamps
+	TopOffenders []PackageIssues `json:"topOffenders"`
+	// Samples lists the most frequent raw strings of every kind of issue
+	Samples []IssueSample `json:"samples"`
+
+	offenders map[string]int
+	samples   map[string]map[string]*IssueSample
+}
+
+// PackageIssues is the number of issues found in the versions of a package.
+type PackageIssues struct {
+	Name   string `json:"name"`
+	Issues int    `json:"issues"`
+}
+
+// IssueSample is a raw string that caused an issue, with the number of times it was found and the first package it
+// was found in, in alphabetical order.
+type IssueSample struct {
+	Issue   string `json:"issue"`
+	Raw     string `json:"raw"`
+	Count   int    `json:"count"`
+	Package string `json:"package"`
+}
+
+func newBuildReport() *BuildReport {
+	return &BuildReport{Ecosystems: make(map[string]*EcosystemReport)}
+}
+
+// ecosystem returns the report of the ecosystem with the given name, and creates it the first time.
+func (r *BuildReport) ecosystem(name string) *EcosystemReport {
+	e, ok := r.Ecosystems[name]
+	if !ok {
+		e = &EcosystemReport{offenders: make(map[string]int), samples: make(map[string]map[string]*IssueSample)}
+		r.Ecosystems[name] = e
+	}
+	return e
+}
+
+// record counts an issue found in the package version stringId of the package packageName. raw is the string that
+// caused the issue. Malformed records have no package name, and their stringId is their position in the input.
+func (e *EcosystemReport) record(issue, packageName, stringId, raw string) {
+	switch issue {
+	case IssueUnparseableVersion:
+		e.UnparseableVersions++
+	case IssueUnparseableConstraint:
+		e.UnparseableConstraints++
+	case IssueUnparseableTimestamp:
+		e.UnparseableTimestamps++
+	case IssueUnresolvedDependency:
+		e.UnresolvedDependencies++
+	case IssueMalformedRecord:
+		e.MalformedRecords++
+	}
+	if issue != IssueUnresolvedDependency && packageName != "" {
+		e.offenders[packageName]++
+	}
+
+	samples, ok := e.samples[issue]
+	if !ok {
+		samples = make(map[string]*IssueSample)
+		e.samples[issue] = samples
+	}
+	if sample, ok := samples[raw]; ok {
+		sample.Count++
+		if stringId < sample.Package {
+			sample.Package = stringId
+		}
+	} else {
+		samples[raw] = &IssueSample{Issue: issue, Raw: raw, Count: 1, Package: stringId}
+	}
+}
+
+// finish fills the top offenders and the samples of every ecosystem. The order only depends on the counts and the
+// strings, so the report does not depend on the order in which the input was read.
+func (r *BuildReport) finish() {
+	for _, e := range r.Ecosystems {
+		e.TopOffenders = make([]PackageIssues, 0, len(e.offenders))
+		for name, count := range e.offenders {
+			e.TopOffenders = append(e.TopOffenders, PackageIssues{Name: name, Issues: count})
+		}
+		sort.Slice(e.TopOffenders, func(i, j int) bool {
+			a, b := e.TopOffenders[i], e.TopOffenders[j]
+			return a.Issues > b.Issues || a.Issues == b.Issues && a.Name < b.Name
+		})
+		if len(e.TopOffenders) > reportTopOffenders {
+			e.TopOffenders = e.TopOffenders[:reportTopOffenders]
+		}
+
+		e.Samples = make([]IssueSample, 0)
+		for _, issue := range reportIssues {
+			samples := make([]IssueSample, 0, len(e.samples[issue]))
+			for _, sample := range e.samples[issue] {
+				samples = append(samples, *sample)
+			}
+			sort.Slice(samples, func(i, j int) bool {
+				a, b := samples[i], samples[j]
+				return a.Count > b.Count || a.Count == b.Count && a.Raw < b.Raw
+			})
+			if len(samples) > reportSamplesPerIssue {
+				samples = samples[:reportSamplesPerIssue]
+			}
+			e.Samples = append(e.Samples, samples...)
+		}
+	}
+}
+
+// String summarizes the report with one line per ecosystem.
+func (r *BuildReport) String() string {
+	names := make([]string, 0, len(r.Ecosystems))
+	for name := range r.Ecosystems {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, len(names))
+	for i, name := range names {
+		e := r.Ecosystems[name]
+		lines[i] = fmt.Sprintf("%s: %d packages, %d versions, %d dependencies and %d edges. Skipped %d unparseable versions, "+
+			"%d unparseable constraints, %d unparseable timestamps, %d unresolved dependencies and %d malformed records",
+			name, e.Packages, e.Versions, e.Dependencies, e.Edges, e.UnparseableVersions, e.UnparseableConstraints,
+			e.UnparseableTimestamps, e.UnresolvedDependencies, e.MalformedRecords)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,65 @@
+package graph
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestCreateEdgesReport(t *testing.T) {
+	packagesInfo := []PackageInfo{
+		{
+			Name: "B",
+			Versions: map[string]VersionInfo{
+				"1.0.0": {
+					Timestamp: "2021-04-22T20:15:37Z",
+					Dependencies: map[string]string{
+						"A": "^1.0.0",
+						"C": "not a constraint",
+						"D": "^1.0.0",
+					},
+				},
+				"banana": {
+					Timestamp: "yesterday",
+					Dependencies: map[string]string{
+						"A": "not a constraint",
+					},
+				},
+			},
+		},
+		{
+			Name: "A",
+			Versions: map[string]VersionInfo{
+				"1.0.0": {Timestamp: "2020-04-01T20:15:37Z"},
+			},
+		},
+	}
+	graph := NewDirectedGraph()
+	index, nodeMap := CreateMaps(&packagesInfo, graph)
+	report, err := CreateEdges(context.Background(), graph, &packagesInfo, index, nodeMap, SemverDialect)
+	if err != nil {
+		t.Fatalf("Creating the edges failed: %v", err)
+	}
+
+	e, ok := report.Ecosystems["semver"]
+	if !ok {
+		t.Fatalf("Expected a report for semver, got %v", report.Ecosystems)
+	}
+	counts := []int{e.Packages, e.Versions, e.Dependencies, e.UnparseableVersions, e.UnparseableConstraints,
+		e.UnparseableTimestamps, e.UnresolvedDependencies}
+	if expected := []int{2, 3, 4, 1, 2, 1, 1}; !reflect.DeepEqual(counts, expected) {
+		t.Errorf("Expected the counts %v, got %v", expected, counts)
+	}
+	if expected := []PackageIssues{{Name: "B", Issues: 4}}; !reflect.DeepEqual(e.TopOffenders, expected) {
+		t.Errorf("Expected the top offenders %v, got %v", expected, e.TopOffenders)
+	}
+	expectedSamples := []IssueSample{
+		{Issue: IssueUnparseableVersion, Raw: "banana", Count: 1, Package: "B-banana"},
+		{Issue: IssueUnparseableConstraint, Raw: "not a constraint", Count: 2, Package: "B-1.0.0"},
+		{Issue: IssueUnparseableTimestamp, Raw: "yesterday", Count: 1, Package: "B-banana"},
+		{Issue: IssueUnresolvedDependency, Raw: "D@^1.0.0", Count: 1, Package: "B-1.0.0"},
+	}
+	if !reflect.DeepEqual(e.Samples, expectedSamples) {
+		t.Errorf("Expected the samples %v, got %v", expectedSamples, e.Samples)
+	}
+}
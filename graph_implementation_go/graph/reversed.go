@@ -0,0 +1,46 @@
+package graph
+
+import (
+	"gonum.org/v1/gonum/graph"
+)
+
+// reversedGraph is a view of a directed graph in which all the edges point the other way around (dependency ->
+// dependent). It allows the gonum traversals, which only follow From, to walk the dependents of a node.
+// The underlying graph must implement To, so a DirectedGraph must have its reverse index enabled.
+type reversedGraph struct {
+	g graph.Directed
+}
+
+var _ graph.Directed = reversedGraph{}
+
+func (r reversedGraph) Node(id int64) graph.Node {
+	return r.g.Node(id)
+}
+
+func (r reversedGraph) Nodes() graph.Nodes {
+	return r.g.Nodes()
+}
+
+func (r reversedGraph) From(id int64) graph.Nodes {
+	return r.g.To(id)
+}
+
+func (r reversedGraph) To(id int64) graph.Nodes {
+	return r.g.From(id)
+}
+
+func (r reversedGraph) HasEdgeBetween(xid, yid int64) bool {
+	return r.g.HasEdgeBetween(xid, yid)
+}
+
+func (r reversedGraph) HasEdgeFromTo(uid, vid int64) bool {
+	return r.g.HasEdgeFromTo(vid, uid)
+}
+
+func (r reversedGraph) Edge(uid, vid int64) graph.Edge {
+	e := r.g.Edge(vid, uid)
+	if e == nil {
+		return nil
+	}
+	return e.ReversedEdge()
+}
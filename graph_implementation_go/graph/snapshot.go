@@ -0,0 +1,344 @@
+package graph
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// The snapshot format stores a built graph so it can be loaded again without parsing the JSON input and checking all
+// the version constraints. All integers are varints (uvarints for counts and lengths) and all strings are prefixed by
+// their length. The layout is:
+//
+//	magic ("STMG") | format version
+//	dialect count | (ecosystem, dialect name) for every ecosystem the index has a dialect for
+//	node count | (id, ecosystem, name, version, timestamp, external) for every node
+//	source count | (from id, edge count, edges...) for every node with outgoing edges
+//	edge: to id, dependency kinds, dependency source, constraint, translated constraint
+//
+// Only the dialects of the index are stored, since the rest of the index is rebuilt from the keys of the nodes. The
+// dialects are stored by name, so the ecosystems keep ordering their versions as they were read. The format version
+// must be increased every time the layout changes, so old snapshots are rejected instead of being misread.
+const (
+	snapshotMagic         = "STMG"
+	snapshotFormatVersion = 7
+
+	// maxSnapshotStringLength guards against allocating huge strings when reading a corrupted snapshot
+	maxSnapshotStringLength = 1 << 20
+)
+
+// ErrSnapshotFormat is returned when a file is not a snapshot or was written by an incompatible version.
+var ErrSnapshotFormat = errors.New("invalid snapshot format")
+
+// WriteSnapshot writes the graph to the file at outPath, so it can be loaded again with LoadSnapshot. Only the part of
+// the graph that its filters selected is written.
+func WriteSnapshot(outPath string, pg *PackageGraph) error {
+	g, idToNodeInfo := pg.directed, pg.nodes
+	f, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	w := &snapshotWriter{w: bufio.NewWriterSize(f, 1<<20)}
+
+	w.writeRaw([]byte(snapshotMagic))
+	w.writeUvarint(snapshotFormatVersion)
+
+	ecosystems := make([]string, 0, len(pg.index.dialects))
+	for ecosystem := range pg.index.dialects {
+		ecosystems = append(ecosystems, ecosystem)
+	}
+	sort.Strings(ecosystems)
+	w.writeUvarint(uint64(len(ecosystems)))
+	for _, ecosystem := range ecosystems {
+		w.writeString(ecosystem)
+		w.writeString(pg.index.dialects[ecosystem].Name())
+	}
+
+	// Sorting the ids makes the snapshot of a graph deterministic
+	nodeIds := make([]int64, 0, len(idToNodeInfo))
+	for id := range idToNodeInfo {
+		if g.Node(id) != nil {
+			nodeIds = append(nodeIds, id)
+		}
+	}
+	sort.Slice(nodeIds, func(i, j int) bool { return nodeIds[i] < nodeIds[j] })
+
+	w.writeUvarint(uint64(len(nodeIds)))
+	for _, id := range nodeIds {
+		info := idToNodeInfo[id]
+		w.writeVarint(id)
+		w.writeString(info.Ecosystem)
+		w.writeString(info.Name)
+		w.writeString(info.Version)
+		w.writeString(info.Timestamp)
+		w.writeBool(info.External)
+	}
+
+	sources := make([]int64, 0, len(nodeIds))
+	for _, id := range nodeIds {
+		if g.From(id).Len() > 0 {
+			sources = append(sources, id)
+		}
+	}
+
+	w.writeUvarint(uint64(len(sources)))
+	for _, id := range sources {
+		targets := make([]int64, 0)
+		for it := g.From(id); it.Next(); {
+			targets = append(targets, it.Node().ID())
+		}
+		sort.Slice(targets, func(i, j int) bool { return targets[i] < targets[j] })
+
+		w.writeVarint(id)
+		w.writeUvarint(uint64(len(targets)))
+		for _, target := range targets {
+			// Edges without dependency information are written as runtime dependencies without constraints
+			e, ok := DependencyEdgeBetween(g, id, target)
+			if !ok {
+				e.Kind = Runtime
+			}
+			w.writeVarint(target)
+			w.writeUvarint(uint64(e.Kind))
+			w.writeUvarint(uint64(e.Source))
+			w.writeString(e.Constraint)
+			w.writeString(e.TranslatedConstraint)
+		}
+	}
+
+	if w.err == nil {
+		w.err = w.w.Flush()
+	}
+	if closeErr := f.Close(); w.err == nil {
+		w.err = closeErr
+	}
+	return w.err
+}
+
+// LoadSnapshot reads a graph written by WriteSnapshot.
+func LoadSnapshot(inPath string) (*PackageGraph, error) {
+	f, err := os.Open(inPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := &snapshotReader{r: bufio.NewReaderSize(f, 1<<20)}
+
+	magic := r.readRaw(len(snapshotMagic))
+	if r.err == nil && string(magic) != snapshotMagic {
+		return nil, fmt.Errorf("%w: %s is not a snapshot", ErrSnapshotFormat, inPath)
+	}
+	if version := r.readUvarint(); r.err == nil && version != snapshotFormatVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d (expected %d)", ErrSnapshotFormat, version, snapshotFormatVersion)
+	}
+
+	directedGraph := NewDirectedGraph()
+	index := NewNodeIndex()
+
+	dialectCount := r.readUvarint()
+	for i := uint64(0); i < dialectCount && r.err == nil; i++ {
+		ecosystem := r.readString()
+		name := r.readString()
+		if r.err != nil {
+			break
+		}
+		dialect, err := DialectByName(name)
+		if err != nil {
+			r.err = fmt.Errorf("%w: %v", ErrSnapshotFormat, err)
+			break
+		}
+		index.SetDialect(ecosystem, dialect)
+	}
+
+	nodeCount := r.readUvarint()
+	idToNodeInfo := make(map[int64]NodeInfo, capacityHint(nodeCount))
+	for i := uint64(0); i < nodeCount && r.err == nil; i++ {
+		id := r.readVarint()
+		ecosystem := r.readString()
+		name := r.readString()
+		version := r.readString()
+		timestamp := r.readString()
+		external := r.readBool()
+		if r.err != nil {
+			break
+		}
+		if directedGraph.Node(id) != nil {
+			r.err = fmt.Errorf("%w: duplicate node %d", ErrSnapshotFormat, id)
+			break
+		}
+		info := NewNodeInfo(id, name, version, timestamp)
+		info.Ecosystem = ecosystem
+		info.External = external
+		if !index.Add(*info) {
+			r.err = fmt.Errorf("%w: duplicate package %s", ErrSnapshotFormat, info.Key())
+			break
+		}
+		idToNodeInfo[id] = *info
+		directedGraph.AddNode(Node(id))
+	}
+
+	// The same constraints are used by a lot of edges, so they are interned to share their memory
+	constraints := make(map[string]string)
+	intern := func(s string) string {
+		if interned, ok := constraints[s]; ok {
+			return interned
+		}
+		constraints[s] = s
+		return s
+	}
+
+	sourceCount := r.readUvarint()
+	for i := uint64(0); i < sourceCount && r.err == nil; i++ {
+		from := directedGraph.Node(r.readVarint())
+		edgeCount := r.readUvarint()
+		for j := uint64(0); j < edgeCount && r.err == nil; j++ {
+			to := directedGraph.Node(r.readVarint())
+			kind := r.readUvarint()
+			source := r.readUvarint()
+			constraint := r.readString()
+			translatedConstraint := r.readString()
+			if r.err != nil {
+				break
+			}
+			if from == nil || to == nil {
+				r.err = fmt.Errorf("%w: edge to an unknown node", ErrSnapshotFormat)
+				break
+			}
+			if kind == 0 || kind&^uint64(Runtime|Dev|Optional|Peer|Test) != 0 {
+				r.err = fmt.Errorf("%w: unknown dependency kind %d", ErrSnapshotFormat, kind)
+				break
+			}
+			if source >= uint64(len(dependencySourceNames)) {
+				r.err = fmt.Errorf("%w: unknown dependency source %d", ErrSnapshotFormat, source)
+				break
+			}
+			directedGraph.SetEdge(DependencyEdge{
+				F:                    from,
+				T:                    to,
+				Constraint:           intern(constraint),
+				TranslatedConstraint: intern(translatedConstraint),
+				Kind:                 DependencyKind(kind),
+				Source:               DependencySource(source),
+			})
+		}
+	}
+
+	if r.err != nil {
+		if errors.Is(r.err, io.EOF) || errors.Is(r.err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("%w: %s is truncated", ErrSnapshotFormat, inPath)
+		}
+		return nil, r.err
+	}
+	return NewPackageGraph(directedGraph, index, idToNodeInfo), nil
+}
+
+// capacityHint bounds a count read from a snapshot before it is used to allocate a map, so a corrupted count cannot
+// make the loader allocate an absurd amount of memory up front.
+func capacityHint(count uint64) int {
+	const maxHint = 1 << 24
+	if count > maxHint {
+		return maxHint
+	}
+	return int(count)
+}
+
+// snapshotWriter keeps the first error that happened while writing, so the callers do not need to check every write.
+type snapshotWriter struct {
+	w   *bufio.Writer
+	buf [binary.MaxVarintLen64]byte
+	err error
+}
+
+func (s *snapshotWriter) writeRaw(b []byte) {
+	if s.err != nil {
+		return
+	}
+	_, s.err = s.w.Write(b)
+}
+
+func (s *snapshotWriter) writeUvarint(x uint64) {
+	n := binary.PutUvarint(s.buf[:], x)
+	s.writeRaw(s.buf[:n])
+}
+
+func (s *snapshotWriter) writeVarint(x int64) {
+	n := binary.PutVarint(s.buf[:], x)
+	s.writeRaw(s.buf[:n])
+}
+
+func (s *snapshotWriter) writeBool(b bool) {
+	if b {
+		s.writeUvarint(1)
+	} else {
+		s.writeUvarint(0)
+	}
+}
+
+func (s *snapshotWriter) writeString(str string) {
+	s.writeUvarint(uint64(len(str)))
+	if s.err != nil {
+		return
+	}
+	_, s.err = s.w.WriteString(str)
+}
+
+// snapshotReader keeps the first error that happened while reading. After an error, all reads return zero values.
+type snapshotReader struct {
+	r   *bufio.Reader
+	err error
+}
+
+func (s *snapshotReader) readRaw(n int) []byte {
+	if s.err != nil {
+		return nil
+	}
+	b := make([]byte, n)
+	_, s.err = io.ReadFull(s.r, b)
+	return b
+}
+
+func (s *snapshotReader) readUvarint() uint64 {
+	if s.err != nil {
+		return 0
+	}
+	var x uint64
+	x, s.err = binary.ReadUvarint(s.r)
+	return x
+}
+
+func (s *snapshotReader) readVarint() int64 {
+	if s.err != nil {
+		return 0
+	}
+	var x int64
+	x, s.err = binary.ReadVarint(s.r)
+	return x
+}
+
+func (s *snapshotReader) readBool() bool {
+	switch x := s.readUvarint(); {
+	case s.err != nil:
+		return false
+	case x > 1:
+		s.err = fmt.Errorf("%w: invalid boolean %d", ErrSnapshotFormat, x)
+		return false
+	default:
+		return x == 1
+	}
+}
+
+func (s *snapshotReader) readString() string {
+	n := s.readUvarint()
+	if s.err != nil {
+		return ""
+	}
+	if n > maxSnapshotStringLength {
+		s.err = fmt.Errorf("%w: string of %d bytes", ErrSnapshotFormat, n)
+		return ""
+	}
+	return string(s.readRaw(int(n)))
+}
@@ -0,0 +1,105 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	graph, index, nodeMap := createDependentsTestGraph()
+	path := filepath.Join(t.TempDir(), "graph.stm")
+
+	if err := WriteSnapshot(path, NewPackageGraph(graph, index, nodeMap)); err != nil {
+		t.Fatalf("Writing the snapshot failed: %v", err)
+	}
+	loaded, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("Loading the snapshot failed: %v", err)
+	}
+	loadedGraph := loaded.Directed().(*DirectedGraph)
+
+	t.Run("Loads the same nodes", func(t *testing.T) {
+		if loadedGraph.Nodes().Len() != graph.Nodes().Len() {
+			t.Errorf("Expected %d nodes, got %d", graph.Nodes().Len(), loadedGraph.Nodes().Len())
+		}
+		for id, expected := range nodeMap {
+			if actual, ok := loaded.Node(id); !ok || expected != actual {
+				t.Errorf("Node info for %d was incorrect (expected: %v, actual %v)", id, expected, actual)
+			}
+		}
+		for id, info := range nodeMap {
+			if loadedInfo, ok := loaded.LookupKey(info.Key()); !ok || loadedInfo.id != id {
+				t.Errorf("Expected %s to point to %d, got %d", info.Key(), id, loadedInfo.id)
+			}
+		}
+	})
+
+	t.Run("Loads the same edges", func(t *testing.T) {
+		if loadedGraph.Edges().Len() != graph.Edges().Len() {
+			t.Errorf("Expected %d edges, got %d", graph.Edges().Len(), loadedGraph.Edges().Len())
+		}
+		for edges := graph.Edges(); edges.Next(); {
+			e := edges.Edge()
+			loaded, ok := DependencyEdgeBetween(loadedGraph, e.From().ID(), e.To().ID())
+			if !ok {
+				t.Errorf("Edge %d -> %d is missing", e.From().ID(), e.To().ID())
+				continue
+			}
+			if expected := e.(DependencyEdge); loaded.Constraint != expected.Constraint || loaded.Kind != expected.Kind ||
+				loaded.Source != expected.Source {
+				t.Errorf("Edge %d -> %d was incorrect (expected: %v, actual %v)", e.From().ID(), e.To().ID(), expected, loaded)
+			}
+		}
+	})
+}
+
+func TestSnapshotKindsAndDialects(t *testing.T) {
+	packagesInfo := []PackageInfo{
+		{Name: "A", Versions: map[string]VersionInfo{"1.0.0": {
+			Timestamp:            "2021-04-22T20:15:37Z",
+			Dependencies:         map[string]string{"B": "1.0.0"},
+			OptionalDependencies: map[string]string{"B": "1.0.0"},
+			PeerDependencies:     map[string]string{"C": "1.0.0"},
+			TestDependencies:     map[string]string{"D": "1.0.0"},
+		}}},
+		{Name: "B", Versions: map[string]VersionInfo{"1.0.0": {Timestamp: "2021-04-01T20:15:37Z"}}},
+		{Name: "C", Versions: map[string]VersionInfo{"1.0.0": {Timestamp: "2021-04-01T20:15:37Z"}}},
+		{Name: "D", Versions: map[string]VersionInfo{"1.0.0": {Timestamp: "2021-04-01T20:15:37Z"}}},
+	}
+	graph := NewDirectedGraph()
+	index, nodeMap := CreateMaps(&packagesInfo, graph)
+	CreateEdges(context.Background(), graph, &packagesInfo, index, nodeMap, SemverDialect)
+	index.SetDialect("maven-central", MavenDialect)
+	path := filepath.Join(t.TempDir(), "graph.stm")
+	if err := WriteSnapshot(path, NewPackageGraph(graph, index, nodeMap)); err != nil {
+		t.Fatalf("Writing the snapshot failed: %v", err)
+	}
+	loaded, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("Loading the snapshot failed: %v", err)
+	}
+
+	if loaded.index.Dialect("maven-central") != MavenDialect {
+		t.Errorf("Expected the dialect of maven-central to be kept, got %s", loaded.index.Dialect("maven-central").Name())
+	}
+	aID := lookupTestNode(index, testKey("A-1.0.0"))
+	for to, expected := range map[string]DependencyKind{"B-1.0.0": Runtime | Optional, "C-1.0.0": Peer, "D-1.0.0": Test} {
+		e, ok := DependencyEdgeBetween(loaded.Directed(), aID, lookupTestNode(index, testKey(to)))
+		if !ok || e.Kind != expected {
+			t.Errorf("Expected the edge to %s to be of kind %v, got %v", to, expected, e)
+		}
+	}
+}
+
+func TestLoadSnapshotRejectsOtherFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "graph.json")
+	if err := os.WriteFile(path, []byte(`{"pkgs": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSnapshot(path); !errors.Is(err, ErrSnapshotFormat) {
+		t.Errorf("Expected ErrSnapshotFormat, got %v", err)
+	}
+}
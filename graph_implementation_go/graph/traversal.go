@@ -0,0 +1,143 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// TraversalOptions limits how far a traversal goes and which nodes it visits. A node that is not visited is not
+// traversed either, so the nodes that can only be reached through it are left out too. The zero value visits the whole
+// closure, like GetTransitiveDependenciesNode.
+type TraversalOptions struct {
+	// MaxDepth is the number of edges the nodes are reached through at most. There is no limit when it is 0
+	MaxDepth int
+	// Kinds selects the kinds of dependencies that are followed, so an edge is followed when it has any of them. All
+	// of them are followed when it is empty
+	Kinds []DependencyKind
+	// Allow lists glob patterns, as accepted by path.Match, of the names of the packages that are visited. All the
+	// packages are visited when it is empty
+	Allow []string
+	// Deny lists glob patterns of the names of the packages that are not visited, even if Allow selects them
+	Deny []string
+	// Keep is called for every node that the other options did not leave out, and the node is visited when it
+	// returns true. All the nodes are visited when it is nil
+	Keep func(NodeInfo) bool
+}
+
+// ReachedNode is a node found by a traversal, with the number of edges between the start of the traversal and the
+// node, and the edge through which the node was reached first. The start of the traversal has a depth of 0 and no edge.
+type ReachedNode struct {
+	NodeInfo
+	Depth int
+	Edge  DependencyEdge
+}
+
+func (node ReachedNode) String() string {
+	return fmt.Sprintf("%v - Depth: %d", node.NodeInfo, node.Depth)
+}
+
+// Validate returns an error when a pattern of the options is malformed. The traversals validate their options first.
+func (options TraversalOptions) Validate() error {
+	for _, patterns := range [][]string{options.Allow, options.Deny} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid package name pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// visits returns whether the options let the traversal visit the node. The patterns must be valid.
+func (options TraversalOptions) visits(node NodeInfo) bool {
+	for _, pattern := range options.Deny {
+		if ok, _ := path.Match(pattern, node.Name); ok {
+			return false
+		}
+	}
+	if len(options.Allow) > 0 {
+		allowed := false
+		for _, pattern := range options.Allow {
+			if ok, _ := path.Match(pattern, node.Name); ok {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return options.Keep == nil || options.Keep(node)
+}
+
+// follows returns whether the options let the traversal follow the edge.
+func (options TraversalOptions) follows(edge DependencyEdge) bool {
+	if len(options.Kinds) == 0 {
+		return true
+	}
+	for _, kind := range options.Kinds {
+		if edge.Kind.Has(kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// TraverseDependencies returns the specified node and its dependencies, in breadth-first order, within the limits of
+// the options. Every node is returned once, with the depth at which it was first reached, so the direct dependencies
+// are the nodes with a depth of 1. The dependencies of every node are visited by name and version, so the result is
+// always the same. The specified node is always returned first, whether the options visit it or not. An error is
+// returned when a pattern of the options is malformed, and the traversal stops with the error of ctx when ctx is
+// cancelled.
+func TraverseDependencies(ctx context.Context, g graph.Directed, nodeMap map[int64]NodeInfo, index *NodeIndex, key NodeKey, options TraversalOptions) (*[]ReachedNode, error) {
+	return traverseFrom(ctx, g, nodeMap, index, key, options, directDependencies)
+}
+
+// TraverseDependents is like TraverseDependencies, but returns the nodes that depend on the specified node. Like
+// GetTransitiveDependentsNode, it enables the reverse index of the graph.
+func TraverseDependents(ctx context.Context, g graph.Directed, nodeMap map[int64]NodeInfo, index *NodeIndex, key NodeKey, options TraversalOptions) (*[]ReachedNode, error) {
+	if ri, ok := g.(reverseIndexer); ok {
+		ri.EnableReverseIndex()
+	}
+	return traverseFrom(ctx, g, nodeMap, index, key, options, directDependents)
+}
+
+// traverseFrom walks the graph in breadth-first order from the specified node, following the edges that next returns.
+func traverseFrom(ctx context.Context, g graph.Directed, nodeMap map[int64]NodeInfo, index *NodeIndex, key NodeKey, options TraversalOptions,
+	next func(g graph.Directed, nodeMap map[int64]NodeInfo, nodeId int64) []Dependency) (*[]ReachedNode, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	result := make([]ReachedNode, 0)
+	rootId, ok := findNode(index, nodeMap, key)
+	if !ok || g.Node(rootId) == nil {
+		return &result, nil // This function is a no-op if we don't have a correct key or the node was filtered out
+	}
+
+	result = append(result, ReachedNode{NodeInfo: nodeMap[rootId]})
+	seen := map[int64]struct{}{rootId: {}}
+	for i := 0; i < len(result); i++ {
+		if i%1024 == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		current := result[i]
+		if options.MaxDepth > 0 && current.Depth == options.MaxDepth {
+			continue
+		}
+		for _, dependency := range next(g, nodeMap, current.id) {
+			if _, ok := seen[dependency.id]; ok || !options.follows(dependency.Edge) {
+				continue
+			}
+			// Whether a node is visited does not depend on the edge that reaches it, so it is only checked once
+			seen[dependency.id] = struct{}{}
+			if !options.visits(dependency.NodeInfo) {
+				continue
+			}
+			result = append(result, ReachedNode{NodeInfo: dependency.NodeInfo, Depth: current.Depth + 1, Edge: dependency.Edge})
+		}
+	}
+	return &result, nil
+}
@@ -0,0 +1,74 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func reachedStrings(nodes []ReachedNode) []string {
+	result := make([]string, len(nodes))
+	for i, node := range nodes {
+		result[i] = fmt.Sprintf("%v:%d", node.Key(), node.Depth)
+	}
+	return result
+}
+
+func TestTraverseDependencies(t *testing.T) {
+	pg := createPathsTestGraph()
+
+	for _, test := range []struct {
+		name     string
+		options  TraversalOptions
+		expected []string
+	}{
+		{"Whole closure", TraversalOptions{},
+			[]string{"R@1.0.0:0", "X@1.0.0:1", "Y@1.0.0:1", "T@1.0.0:2", "T@2.0.0:2", "Z@1.0.0:2"}},
+		{"Maximum depth", TraversalOptions{MaxDepth: 1}, []string{"R@1.0.0:0", "X@1.0.0:1", "Y@1.0.0:1"}},
+		{"Denied names", TraversalOptions{Deny: []string{"X"}}, []string{"R@1.0.0:0", "Y@1.0.0:1", "Z@1.0.0:2"}},
+		{"Allowed names", TraversalOptions{Allow: []string{"[RXY]"}}, []string{"R@1.0.0:0", "X@1.0.0:1", "Y@1.0.0:1"}},
+		{"Node predicate", TraversalOptions{Keep: func(node NodeInfo) bool { return node.Version != "2.0.0" }},
+			[]string{"R@1.0.0:0", "X@1.0.0:1", "Y@1.0.0:1", "T@1.0.0:2", "Z@1.0.0:2"}},
+		{"Kinds", TraversalOptions{Kinds: []DependencyKind{Dev}}, []string{"R@1.0.0:0"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			nodes, err := pg.TraverseDependencies(context.Background(), testKey("R-1.0.0"), test.options)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if actual := reachedStrings(nodes); !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+
+	t.Run("Dependents", func(t *testing.T) {
+		nodes, err := pg.TraverseDependents(context.Background(), testKey("T-1.0.0"), TraversalOptions{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		expected := []string{"T@1.0.0:0", "X@1.0.0:1", "R@1.0.0:2", "Y@1.0.0:2", "Z@1.0.0:3"}
+		if actual := reachedStrings(nodes); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Expected %v, got %v", expected, actual)
+		}
+		if nodes[1].Edge.Constraint != ">=1.0.0" {
+			t.Errorf("Expected X to be reached through >=1.0.0, got %v", nodes[1].Edge)
+		}
+	})
+
+	t.Run("Invalid pattern", func(t *testing.T) {
+		if _, err := pg.TraverseDependencies(context.Background(), testKey("R-1.0.0"), TraversalOptions{Deny: []string{"["}}); err == nil {
+			t.Errorf("Expected an error for a malformed pattern")
+		}
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := pg.TraverseDependents(ctx, testKey("T-1.0.0"), TraversalOptions{}); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected the traversal to be cancelled, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package graph
+
+import (
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/iterator"
+)
+
+// GraphView is a read-only view of a directed graph that only contains the nodes accepted by a node predicate, and the
+// edges between them that are accepted by an edge predicate. A view does not copy or modify the graph it was created
+// from, so views can be stacked on top of each other and thrown away when they are no longer needed.
+type GraphView struct {
+	base     graph.Directed
+	keep     func(id int64) bool
+	keepEdge func(e graph.Edge) bool
+}
+
+var _ graph.Directed = (*GraphView)(nil)
+
+// NewGraphView returns a view of base that only contains the nodes for which keep returns true.
+func NewGraphView(base graph.Directed, keep func(id int64) bool) *GraphView {
+	return &GraphView{base: base, keep: keep}
+}
+
+// NewEdgeView returns a view of base that contains all of its nodes, but only the edges for which keepEdge returns true.
+func NewEdgeView(base graph.Directed, keepEdge func(e graph.Edge) bool) *GraphView {
+	return &GraphView{base: base, keep: func(int64) bool { return true }, keepEdge: keepEdge}
+}
+
+// newSetView returns a view of base that only contains the nodes in keepIDs.
+func newSetView(base graph.Directed, keepIDs map[int64]struct{}) *GraphView {
+	return NewGraphView(base, func(id int64) bool {
+		_, ok := keepIDs[id]
+		return ok
+	})
+}
+
+// Node returns the node with the given ID if it exists in the view, and nil otherwise.
+func (v *GraphView) Node(id int64) graph.Node {
+	if !v.keep(id) {
+		return nil
+	}
+	return v.base.Node(id)
+}
+
+// Nodes returns all the nodes in the view.
+func (v *GraphView) Nodes() graph.Nodes {
+	return v.filter(v.base.Nodes(), nil)
+}
+
+// From returns all nodes in the view that can be reached directly from the node with the given ID.
+func (v *GraphView) From(id int64) graph.Nodes {
+	if !v.keep(id) {
+		return graph.Empty
+	}
+	return v.filter(v.base.From(id), func(n graph.Node) graph.Edge { return v.base.Edge(id, n.ID()) })
+}
+
+// To returns all nodes in the view that can reach directly to the node with the given ID. Like DirectedGraph.To, it
+// needs the reverse index of the underlying graph.
+func (v *GraphView) To(id int64) graph.Nodes {
+	if !v.keep(id) {
+		return graph.Empty
+	}
+	return v.filter(v.base.To(id), func(n graph.Node) graph.Edge { return v.base.Edge(n.ID(), id) })
+}
+
+// HasEdgeBetween returns whether an edge exists between nodes x and y without considering direction.
+func (v *GraphView) HasEdgeBetween(xid, yid int64) bool {
+	return v.HasEdgeFromTo(xid, yid) || v.HasEdgeFromTo(yid, xid)
+}
+
+// HasEdgeFromTo returns whether an edge exists in the view from u to v.
+func (v *GraphView) HasEdgeFromTo(uid, vid int64) bool {
+	return v.Edge(uid, vid) != nil
+}
+
+// Edge returns the edge from u to v if such an edge exists in the view and nil otherwise.
+func (v *GraphView) Edge(uid, vid int64) graph.Edge {
+	if !v.keep(uid) || !v.keep(vid) {
+		return nil
+	}
+	e := v.base.Edge(uid, vid)
+	if e == nil || (v.keepEdge != nil && !v.keepEdge(e)) {
+		return nil
+	}
+	return e
+}
+
+// EnableReverseIndex enables the reverse index of the underlying graph, if it has one.
+func (v *GraphView) EnableReverseIndex() {
+	if ri, ok := v.base.(reverseIndexer); ok {
+		ri.EnableReverseIndex()
+	}
+}
+
+// filter returns the nodes that are in the view. When edge is not nil, it returns the edge that connects each node to
+// the node the nodes were reached from, and only the nodes whose edge is in the view are returned.
+func (v *GraphView) filter(nodes graph.Nodes, edge func(n graph.Node) graph.Edge) graph.Nodes {
+	var kept []graph.Node
+	for nodes.Next() {
+		n := nodes.Node()
+		if !v.keep(n.ID()) {
+			continue
+		}
+		if edge != nil && v.keepEdge != nil && !v.keepEdge(edge(n)) {
+			continue
+		}
+		kept = append(kept, n)
+	}
+	if len(kept) == 0 {
+		return graph.Empty
+	}
+	return iterator.NewOrderedNodes(kept)
+}
+
+// reverseIndexer is implemented by the graphs that build their reverse index on demand.
+type reverseIndexer interface {
+	EnableReverseIndex()
+}
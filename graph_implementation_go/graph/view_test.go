@@ -0,0 +1,48 @@
+package graph
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilterViewLeavesGraphIntact(t *testing.T) {
+	graph, index, nodeMap := createDependentsTestGraph()
+	nodesBefore := graph.Nodes().Len()
+	edgesBefore := graph.Edges().Len()
+
+	beginTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	endTime := time.Date(2021, 12, 31, 0, 0, 0, 0, time.UTC)
+	view := FilterView(graph, nodeMap, beginTime, endTime)
+
+	t.Run("Only keeps the nodes released in the interval", func(t *testing.T) {
+		if view.Nodes().Len() != 3 {
+			t.Errorf("Expected 3 nodes in the view, got %d", view.Nodes().Len())
+		}
+		if view.Node(nodeMap[lookupTestNode(index, NodeKey{Name: "C", Version: "1.0.0"})].id) != nil {
+			t.Error("Expected C-1.0.0 to be filtered out")
+		}
+	})
+
+	t.Run("Does not modify the graph", func(t *testing.T) {
+		if graph.Nodes().Len() != nodesBefore || graph.Edges().Len() != edgesBefore {
+			t.Errorf("Expected %d nodes and %d edges, got %d and %d", nodesBefore, edgesBefore, graph.Nodes().Len(), graph.Edges().Len())
+		}
+	})
+
+	t.Run("Stacks with other views", func(t *testing.T) {
+		latest := FilterLatestView(view, nodeMap, index)
+		if latest.Nodes().Len() != 2 {
+			t.Errorf("Expected 2 nodes (A-2.0.0 and B-1.0.0), got %d", latest.Nodes().Len())
+		}
+		if latest.Node(nodeMap[lookupTestNode(index, NodeKey{Name: "A", Version: "2.0.0"})].id) == nil {
+			t.Error("Expected A-2.0.0 to be the latest release of A")
+		}
+	})
+
+	t.Run("Traverses only the nodes in the view", func(t *testing.T) {
+		deps := GetTransitiveDependenciesNode(view, nodeMap, index, testKey("C-1.0.0"))
+		if len(*deps) != 0 {
+			t.Errorf("Expected no result for a filtered out root, got %v", *deps)
+		}
+	})
+}
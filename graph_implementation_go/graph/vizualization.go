@@ -1,52 +1,52 @@
 package graph
 
 import (
+	"bufio"
 	"fmt"
 	"gonum.org/v1/gonum/graph/encoding/dot"
-	"log"
 	"os"
 )
 
 // Visualization writes the simple graph to a dot file, so it could be visualized with GraphViz. This includes only Ids
-func Visualization(graph *DirectedGraph, name string) {
-	result, _ := dot.Marshal(graph, name, "", "  ")
-
-	file, err := os.Create(name + ".dot")
-
+// and the labels of the edges
+func Visualization(graph *DirectedGraph, name string) error {
+	result, err := dot.Marshal(graph, name, "", "  ")
 	if err != nil {
-		log.Fatal("Error!", err)
+		return err
 	}
-	defer file.Close()
-
-	fmt.Fprint(file, string(result))
 
+	return os.WriteFile(name+".dot", result, 0o644)
 }
 
-// VisualizationNodeInfo writes to dot file manually from the NodeInfoMap to include the Node info in the graphViz
-func VisualizationNodeInfo(iDToNodeInfo map[int64]NodeInfo, graph *DirectedGraph, name string) {
+// VisualizationNodeInfo writes to dot file manually from the NodeInfoMap to include the Node info in the graphViz.
+// The edges are labeled with their constraint and dependency kind
+func VisualizationNodeInfo(iDToNodeInfo map[int64]NodeInfo, graph *DirectedGraph, name string) error {
 	file, err := os.Create(name + ".dot")
-	d1 := []byte("strict digraph" + " " + name + " " + "{\n")
-	d2 := []byte("}")
-	lab := string("[label = \" ")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
 	edgIt := graph.Edges()
 
-	fmt.Fprint(file, string(d1))
+	fmt.Fprint(w, "strict digraph "+name+" {\n")
 
 	for _, element := range iDToNodeInfo {
-		fmt.Fprintf(file, fmt.Sprint(element.id)+lab+element.Name+` \n `+element.Version+` \n `+element.Timestamp+"\""+"];\n")
-
+		fmt.Fprintf(w, "%d[label = \" %s \\n %s \\n %s\"];\n", element.id, element.Name, element.Version, element.Timestamp)
 	}
 
 	for edgIt.Next() {
-		fmt.Fprintf(file, fmt.Sprint(edgIt.Edge().From().ID())+" -> "+fmt.Sprint(edgIt.Edge().To().ID())+";\n")
+		label := ""
+		if e, ok := edgIt.Edge().(DependencyEdge); ok { // Show why the edge exists
+			label = fmt.Sprintf(" [label = %q]", e.String())
+		}
+		fmt.Fprintf(w, "%d -> %d%s;\n", edgIt.Edge().From().ID(), edgIt.Edge().To().ID(), label)
 	}
 
-	fmt.Fprint(file, string(d2))
+	fmt.Fprint(w, "}")
 
-	if err != nil {
-		panic(err)
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
 	}
-
-	defer file.Close()
-
+	return file.Close()
 }
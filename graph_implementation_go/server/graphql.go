@@ -0,0 +1,359 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/graphql-go/graphql"
+)
+
+// graphQLRequest is the body of a POST request to /graphql. GET requests give the same fields as query parameters,
+// with the variables encoded as JSON.
+type graphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// graphQL answers the GraphQL queries of /graphql. The query is run on the graph as a whole, and the arguments of the
+// fields select the part of the graph they see, so the selections of a single query can use different time windows.
+// Errors are reported in the errors of the result, as GraphQL expects, and only a request that cannot be read is
+// answered with another status than 200.
+func (s *Server) graphQL(w http.ResponseWriter, r *http.Request) {
+	var request graphQLRequest
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		request.Query, request.OperationName = query.Get("query"), query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "variables must be a JSON object"})
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "the body must be a JSON object with a query"})
+			return
+		}
+	default:
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "only GET and POST are allowed"})
+		return
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         s.schema,
+		RequestString:  request.Query,
+		OperationName:  request.OperationName,
+		VariableValues: request.Variables,
+		RootObject:     map[string]any{"views": &viewCache{server: s, views: make(map[string]*g.PackageGraph)}},
+		Context:        r.Context(),
+	})
+	writeJSON(w, http.StatusOK, result)
+}
+
+// viewCache holds the views of the graph used by a single query, so the fields of the query that have the same
+// arguments share the same view instead of filtering the graph again.
+type viewCache struct {
+	server *Server
+	mu     sync.Mutex
+	views  map[string]*g.PackageGraph
+}
+
+// get returns the view selected by the from, to and kind arguments of a field.
+func (c *viewCache) get(args map[string]any) (*g.PackageGraph, error) {
+	from, _ := args["from"].(string)
+	to, _ := args["to"].(string)
+	kinds := stringList(args["kind"])
+	sortedKinds := append([]string(nil), kinds...)
+	sort.Strings(sortedKinds)
+	key := fmt.Sprintf("from=%s&to=%s&kind=%v", from, to, sortedKinds)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if view, ok := c.views[key]; ok {
+		return view, nil
+	}
+	view, _, err := c.server.filter(from, to, kinds)
+	if err != nil {
+		return nil, err
+	}
+	c.views[key] = view
+	return view, nil
+}
+
+func viewsOf(p graphql.ResolveParams) *viewCache {
+	return p.Info.RootValue.(map[string]any)["views"].(*viewCache)
+}
+
+const (
+	// maxGraphQLDepth is the largest depth argument of the dependencies and the dependents
+	maxGraphQLDepth = 10
+	// maxGraphQLNesting is the largest number of lists that a dependencies or dependents field can be nested in, plus
+	// one. Every level runs a traversal for every version of the previous one, so deeper queries quickly add up
+	maxGraphQLNesting = 3
+)
+
+// packageSource is a package, which is the set of versions of a name in an ecosystem.
+type packageSource struct {
+	ecosystem string
+	name      string
+}
+
+// newSchema returns the GraphQL schema of the server:
+//
+//	type Query {
+//	  package(name: String!, ecosystem: String): Package
+//	  version(name: String!, version: String!, ecosystem: String): Version
+//	}
+//	type Package {
+//	  ecosystem: String!
+//	  name: String!
+//	  versions(from: String, to: String): [Version!]!
+//	}
+//	type Version {
+//	  ecosystem: String!
+//	  name: String!
+//	  version: String!
+//	  timestamp: String!
+//	  external: Boolean!
+//	  dependencies(from: String, to: String, kind: [String!], depth: Int = 1, allow: [String!], deny: [String!]): [Edge!]!
+//	  dependents(from: String, to: String, kind: [String!], depth: Int = 1, allow: [String!], deny: [String!]): [Edge!]!
+//	}
+//	type Edge {
+//	  node: Version!
+//	  constraint: String!
+//	  kind: String!
+//	  depth: Int!
+//	}
+//
+// The from and to arguments are dates in the DD-MM-YYYY format, and only keep the versions released between them.
+// The kind arguments only follow the dependencies of the given kinds. With a depth larger than 1, dependencies and
+// dependents also return the versions that are up to depth edges away, each with the edge through which it was
+// reached first. The allow and deny arguments are glob patterns of the names of the packages that are visited or
+// not, as in TraversalOptions. Since the server is shared, depth is at most maxGraphQLDepth and the dependencies and
+// dependents can only be nested in maxGraphQLNesting-1 lists, such as the versions of a package or other
+// dependencies.
+func (s *Server) newSchema() (graphql.Schema, error) {
+	windowArgs := graphql.FieldConfigArgument{
+		"from": {Type: graphql.String, Description: "only keep the versions released from this date (DD-MM-YYYY)"},
+		"to":   {Type: graphql.String, Description: "only keep the versions released until this date (DD-MM-YYYY)"},
+	}
+	traversalArgs := graphql.FieldConfigArgument{
+		"kind":  {Type: graphql.NewList(graphql.NewNonNull(graphql.String)), Description: "only follow these kinds"},
+		"depth": {Type: graphql.Int, DefaultValue: 1, Description: "the largest number of edges to follow"},
+		"allow": {Type: graphql.NewList(graphql.NewNonNull(graphql.String)), Description: "only visit these names"},
+		"deny":  {Type: graphql.NewList(graphql.NewNonNull(graphql.String)), Description: "never visit these names"},
+	}
+	for name, arg := range windowArgs {
+		traversalArgs[name] = arg
+	}
+
+	var versionType *graphql.Object
+	edgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Edge",
+		Description: "A version reached from another version, with the first edge through which it was reached",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"node": {Type: graphql.NewNonNull(versionType), Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(g.ReachedNode).NodeInfo, nil
+				}},
+				"constraint": {Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(g.ReachedNode).Edge.Constraint, nil
+				}},
+				"kind": {Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(g.ReachedNode).Edge.Kind.String(), nil
+				}},
+				"depth": {Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(g.ReachedNode).Depth, nil
+				}},
+			}
+		}),
+	})
+	versionType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Version",
+		Description: "A version of a package, which is a node of the graph",
+		Fields: graphql.Fields{
+			"ecosystem": {Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(g.NodeInfo).Ecosystem, nil
+			}},
+			"name": {Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(g.NodeInfo).Name, nil
+			}},
+			"version": {Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(g.NodeInfo).Version, nil
+			}},
+			"timestamp": {Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(g.NodeInfo).Timestamp, nil
+			}},
+			"external": {Type: graphql.NewNonNull(graphql.Boolean), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(g.NodeInfo).External, nil
+			}},
+			"dependencies": {
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType))),
+				Args: traversalArgs,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return traverse(p, (*g.PackageGraph).TraverseDependencies)
+				},
+			},
+			"dependents": {
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType))),
+				Args: traversalArgs,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return traverse(p, (*g.PackageGraph).TraverseDependents)
+				},
+			},
+		},
+	})
+	packageType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Package",
+		Description: "A package, made of all the versions of a name in an ecosystem",
+		Fields: graphql.Fields{
+			"ecosystem": {Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(packageSource).ecosystem, nil
+			}},
+			"name": {Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(packageSource).name, nil
+			}},
+			"versions": {
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(versionType))),
+				Args: windowArgs,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					view, err := viewsOf(p).get(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					pkg := p.Source.(packageSource)
+					var versions []g.NodeInfo
+					for _, info := range view.Versions(pkg.name) {
+						if info.Ecosystem == pkg.ecosystem {
+							versions = append(versions, info)
+						}
+					}
+					return versions, nil
+				},
+			},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"package": {
+				Type: packageType,
+				Args: graphql.FieldConfigArgument{
+					"name":      {Type: graphql.NewNonNull(graphql.String)},
+					"ecosystem": {Type: graphql.String, Description: "only needed when several ecosystems have the name"},
+				},
+				Resolve: s.resolvePackage,
+			},
+			"version": {
+				Type: versionType,
+				Args: graphql.FieldConfigArgument{
+					"name":      {Type: graphql.NewNonNull(graphql.String)},
+					"version":   {Type: graphql.NewNonNull(graphql.String)},
+					"ecosystem": {Type: graphql.String, Description: "only needed when several ecosystems have the version"},
+				},
+				Resolve: s.resolveVersion,
+			},
+		},
+	})
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// resolvePackage returns the package with the given name, or nil when no ecosystem has it.
+func (s *Server) resolvePackage(p graphql.ResolveParams) (any, error) {
+	name, _ := p.Args["name"].(string)
+	ecosystem, _ := p.Args["ecosystem"].(string)
+	var found []string
+	for _, info := range s.pg.Versions(name) {
+		if (ecosystem == "" || info.Ecosystem == ecosystem) && (len(found) == 0 || found[len(found)-1] != info.Ecosystem) {
+			found = append(found, info.Ecosystem)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, nil
+	case 1:
+		return packageSource{ecosystem: found[0], name: name}, nil
+	default:
+		return nil, fmt.Errorf("%w: ecosystem must be given, %s is in %s", errBadRequest, name, strings.Join(found, ", "))
+	}
+}
+
+// resolveVersion returns the version with the given name and version, or nil when it is not in the graph.
+func (s *Server) resolveVersion(p graphql.ResolveParams) (any, error) {
+	key := g.NodeKey{Name: p.Args["name"].(string), Version: p.Args["version"].(string)}
+	key.Ecosystem, _ = p.Args["ecosystem"].(string)
+	key, err := s.completeKey(key)
+	if err != nil {
+		if errors.Is(err, g.ErrPackageNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if info, ok := s.pg.LookupKey(key); ok {
+		return info, nil
+	}
+	return nil, nil
+}
+
+// traverse returns the versions that are at most depth edges away from the version of p, in breadth-first order,
+// with the traversal given by walk. Every version is returned once, with the first edge through which it was reached.
+// A version that is not in the view selected by the arguments of p has no dependencies or dependents. The traversal
+// stops with the error of the context of the query when the client goes away.
+func traverse(p graphql.ResolveParams, walk func(pg *g.PackageGraph, ctx context.Context, key g.NodeKey, options g.TraversalOptions) ([]g.ReachedNode, error)) (any, error) {
+	options := g.TraversalOptions{Allow: stringList(p.Args["allow"]), Deny: stringList(p.Args["deny"])}
+	options.MaxDepth, _ = p.Args["depth"].(int)
+	if options.MaxDepth < 1 || options.MaxDepth > maxGraphQLDepth {
+		return nil, fmt.Errorf("%w: depth must be between 1 and %d", errBadRequest, maxGraphQLDepth)
+	}
+	if nesting(p.Info.Path) > maxGraphQLNesting {
+		return nil, fmt.Errorf("%w: dependencies and dependents can only be nested %d levels deep", errBadRequest, maxGraphQLNesting)
+	}
+	if err := options.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errBadRequest, err)
+	}
+	view, err := viewsOf(p).get(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	root := p.Source.(g.NodeInfo)
+	if _, ok := view.LookupKey(root.Key()); !ok {
+		return []g.ReachedNode{}, nil
+	}
+	reached, err := walk(view, p.Context, root.Key(), options)
+	if err != nil {
+		return nil, err
+	}
+	return reached[1:], nil // The version itself is reached first
+}
+
+// nesting returns one plus the number of lists that the field at path is in.
+func nesting(path *graphql.ResponsePath) int {
+	result := 1
+	for ; path != nil; path = path.Prev {
+		if _, ok := path.Key.(int); ok {
+			result++
+		}
+	}
+	return result
+}
+
+// stringList returns the strings of a list argument, which is nil when the argument was not given.
+func stringList(arg any) []string {
+	list, _ := arg.([]any)
+	result := make([]string, 0, len(list))
+	for _, value := range list {
+		result = append(result, value.(string))
+	}
+	return result
+}
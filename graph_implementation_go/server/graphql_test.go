@@ -0,0 +1,184 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// postGraphQL sends the query to /graphql and decodes the data of the result into data. It returns the messages of
+// the errors of the result.
+func postGraphQL(t *testing.T, s *Server, query string, data any) []string {
+	body, _ := json.Marshal(graphQLRequest{Query: query})
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for %s, got %d", query, recorder.Code)
+	}
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&result); err != nil {
+		t.Fatalf("Decoding the result of %s failed: %v", query, err)
+	}
+	if len(result.Errors) == 0 {
+		if err := json.Unmarshal(result.Data, data); err != nil {
+			t.Fatalf("Decoding the data of %s failed: %v", query, err)
+		}
+	}
+	messages := make([]string, len(result.Errors))
+	for i, e := range result.Errors {
+		messages[i] = e.Message
+	}
+	return messages
+}
+
+type graphQLEdge struct {
+	Node struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"node"`
+	Constraint string `json:"constraint"`
+	Depth      int    `json:"depth"`
+}
+
+// edgeKeys returns name@version:depth for every edge.
+func edgeKeys(edges []graphQLEdge) []string {
+	result := make([]string, len(edges))
+	for i, edge := range edges {
+		result[i] = fmt.Sprintf("%s@%s:%d", edge.Node.Name, edge.Node.Version, edge.Depth)
+	}
+	return result
+}
+
+func TestGraphQLQueries(t *testing.T) {
+	s := createTestServer(t)
+
+	t.Run("Nested versions and dependencies", func(t *testing.T) {
+		var data struct {
+			Package struct {
+				Ecosystem string `json:"ecosystem"`
+				Versions  []struct {
+					Version    string `json:"version"`
+					Dependents []struct {
+						Node struct {
+							Name         string        `json:"name"`
+							Dependencies []graphQLEdge `json:"dependencies"`
+						} `json:"node"`
+					} `json:"dependents"`
+				} `json:"versions"`
+			} `json:"package"`
+		}
+		errs := postGraphQL(t, s, `{ package(name: "A") { ecosystem versions(from: "01-01-2021", to: "01-05-2021") {
+			version dependents { node { name dependencies { node { name version } constraint depth } } } } } }`, &data)
+		if len(errs) > 0 {
+			t.Fatalf("Expected no errors, got %v", errs)
+		}
+		versions := data.Package.Versions
+		if data.Package.Ecosystem != "" || len(versions) != 1 || versions[0].Version != "1.0.0" {
+			t.Fatalf("Expected only A@1.0.0 to be released in the time window, got %+v", data.Package)
+		}
+		if len(versions[0].Dependents) != 1 || versions[0].Dependents[0].Node.Name != "B" {
+			t.Fatalf("Expected B to depend on A@1.0.0, got %+v", versions[0].Dependents)
+		}
+		dependencies := versions[0].Dependents[0].Node.Dependencies
+		if !reflect.DeepEqual(edgeKeys(dependencies), []string{"A@1.0.0:1", "A@2.0.0:1"}) || dependencies[0].Constraint != ">=1.0.0" {
+			t.Errorf("Expected B to depend on both versions of A through >=1.0.0, got %+v", dependencies)
+		}
+	})
+
+	for _, test := range []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{"Depth limit", `{ version(name: "C", version: "1.0.0") { edges: dependencies(depth: 2) { node { name version } depth } } }`,
+			[]string{"B@1.0.0:1", "A@1.0.0:2", "A@2.0.0:2"}},
+		{"Direct dependencies", `{ version(name: "C", version: "1.0.0") { edges: dependencies { node { name version } depth } } }`,
+			[]string{"B@1.0.0:1"}},
+		{"Dependents", `{ version(name: "A", version: "1.0.0") { edges: dependents(depth: 5) { node { name version } depth } } }`,
+			[]string{"B@1.0.0:1", "C@1.0.0:2"}},
+		{"Time window", `{ version(name: "A", version: "1.0.0") { edges: dependents(depth: 5, from: "01-01-2021", to: "31-12-2021") {
+			node { name version } depth } } }`, []string{"B@1.0.0:1"}},
+		{"Denied names", `{ version(name: "C", version: "1.0.0") { edges: dependencies(depth: 2, deny: ["B"]) { node { name version } depth } } }`,
+			[]string{}},
+		{"Kinds", `{ version(name: "C", version: "1.0.0") { edges: dependencies(kind: ["dev"]) { node { name version } depth } } }`,
+			[]string{}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var data struct {
+				Version struct {
+					Edges []graphQLEdge `json:"edges"`
+				} `json:"version"`
+			}
+			if errs := postGraphQL(t, s, test.query, &data); len(errs) > 0 {
+				t.Fatalf("Expected no errors, got %v", errs)
+			}
+			if actual := edgeKeys(data.Version.Edges); !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+
+	t.Run("Unknown packages are null", func(t *testing.T) {
+		var data map[string]any
+		if errs := postGraphQL(t, s, `{ package(name: "D") { name } version(name: "A", version: "3.0.0") { name } }`, &data); len(errs) > 0 {
+			t.Fatalf("Expected no errors, got %v", errs)
+		}
+		if data["package"] != nil || data["version"] != nil {
+			t.Errorf("Expected null results, got %v", data)
+		}
+	})
+
+	t.Run("Invalid arguments", func(t *testing.T) {
+		for _, query := range []string{
+			`{ version(name: "C", version: "1.0.0") { dependencies(depth: 0) { depth } } }`,
+			`{ version(name: "C", version: "1.0.0") { dependencies(depth: 1000000) { depth } } }`,
+			`{ version(name: "A", version: "1.0.0") { dependents { node { dependencies { node { dependents {
+				node { dependencies { depth } } } } } } } } }`,
+			`{ version(name: "C", version: "1.0.0") { dependencies(from: "today", to: "tomorrow") { depth } } }`,
+			`{ version(name: "C", version: "1.0.0") { dependencies(kind: ["build"]) { depth } } }`,
+			`{ version(name: "C", version: "1.0.0") { dependencies(allow: ["["]) { depth } } }`,
+			`{ version(name: "C") { name } }`,
+		} {
+			var data any
+			if errs := postGraphQL(t, s, query, &data); len(errs) == 0 {
+				t.Errorf("Expected an error for %s", query)
+			}
+		}
+	})
+}
+
+func TestGraphQLGet(t *testing.T) {
+	s := createTestServer(t)
+
+	var result struct {
+		Data struct {
+			Version struct {
+				Name string `json:"name"`
+			} `json:"version"`
+		} `json:"data"`
+	}
+	query := url.Values{
+		"query":     {`query($v: String!) { version(name: "B", version: $v) { name } }`},
+		"variables": {`{"v": "1.0.0"}`},
+	}
+	target := "/graphql?" + query.Encode()
+	if status := get(t, s, target, &result); status != http.StatusOK || result.Data.Version.Name != "B" {
+		t.Errorf("Expected B, got %d %+v", status, result)
+	}
+
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/graphql", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected PUT to be refused, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,495 @@
+// Package server exposes the queries of a PackageGraph over HTTP. Every endpoint answers GET requests with JSON, and
+// takes its arguments as query parameters named like the flags of the commands:
+//
+//	/dependencies?package=lodash&version=4.17.20[&ecosystem=npm][&direct=true]
+//	/dependents?package=lodash&version=4.17.20
+//	/resolve?package=lodash&version=4.17.20[&as-of=01-01-2021]
+//	/between?from=01-01-2020&to=01-01-2021
+//	/rank?top=10
+//	/betweenness?top=10
+//	/graphql
+//
+// All of them but /between and /betweenness also accept from and to, to only take the packages released between the
+// two dates into account, and kind, which can be repeated, to only follow the dependencies of the given kinds. The
+// dates use the DD-MM-YYYY format of the commands.
+//
+// /graphql answers GraphQL queries, given in the query parameter of a GET request or in the body of a POST request,
+// which explore the dependencies and the dependents of the packages selectively. See newSchema for the schema.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+	"github.com/graphql-go/graphql"
+)
+
+// dateLayout is the layout of the dates of the query parameters (DD-MM-YYYY), the same as the one of the commands.
+const dateLayout = "02-01-2006"
+
+// defaultTop is the number of results of /rank and /betweenness when top is not given.
+const defaultTop = 10
+
+const (
+	// maxCentralityResults is the number of sets of scores kept by the cache of the centrality scores
+	maxCentralityResults = 16
+	// maxCentralityComputations is the number of centrality scores that can be computed at the same time
+	maxCentralityComputations = 2
+)
+
+// errBadRequest is wrapped by the errors caused by invalid query parameters.
+var errBadRequest = errors.New("bad request")
+
+// errBusy is returned when too many centrality scores are being computed to start another computation.
+var errBusy = errors.New("too many computations in progress")
+
+// Server answers the queries on a PackageGraph. The graph is only read, so the requests are served concurrently.
+type Server struct {
+	ctx        context.Context
+	pg         *g.PackageGraph
+	mux        *http.ServeMux
+	schema     graphql.Schema
+	centrality centralityCache
+}
+
+// New returns a Server for the graph. The PageRank and betweenness scores are computed once for every set of filters
+// and shared by all the requests. Their computation is bounded by ctx rather than by the request that started it, so
+// it is not wasted when that request goes away. Only a few computations run at the same time, and only the scores of
+// the most recently used filters are kept.
+func New(ctx context.Context, pg *g.PackageGraph) *Server {
+	s := &Server{ctx: ctx, pg: pg, mux: http.NewServeMux()}
+	s.centrality.results = make(map[string]*centralityResult)
+	s.centrality.running = make(chan struct{}, maxCentralityComputations)
+	s.handle("/dependencies", s.dependencies)
+	s.handle("/dependents", s.dependents)
+	s.handle("/resolve", s.resolve)
+	s.handle("/between", s.between)
+	s.handle("/rank", s.rank)
+	s.handle("/betweenness", s.betweenness)
+
+	schema, err := s.newSchema()
+	if err != nil {
+		panic(fmt.Sprintf("server: invalid GraphQL schema: %v", err)) // The schema does not depend on the graph
+	}
+	s.schema = schema
+	s.mux.HandleFunc("/graphql", s.graphQL)
+
+	// The reverse index is built up front, so the first query of the dependents does not have to wait for it
+	pg.EnableReverseIndex()
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handle registers an endpoint. The value returned by the endpoint is written as JSON, and its error is written as
+// {"error": "..."} with a status code that depends on the error.
+func (s *Server) handle(path string, endpoint func(r *http.Request) (any, error)) {
+	s.mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "only GET is allowed"})
+			return
+		}
+		result, err := endpoint(r)
+		if err != nil {
+			writeJSON(w, statusOf(err), errorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	})
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// statusOf returns the status code of the response to a request that failed with err.
+func statusOf(err error) int {
+	var timestampErr *g.TimestampParseError
+	switch {
+	case errors.Is(err, errBadRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, g.ErrPackageNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded), errors.Is(err, errBusy):
+		return http.StatusServiceUnavailable
+	case errors.As(err, &timestampErr):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func (s *Server) dependencies(r *http.Request) (any, error) {
+	view, key, err := s.viewAndKey(r)
+	if err != nil {
+		return nil, err
+	}
+	if direct, _ := strconv.ParseBool(r.URL.Query().Get("direct")); direct {
+		dependencies, err := view.Dependencies(key)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]dependencyResponse, len(dependencies))
+		for i, dependency := range dependencies {
+			result[i] = dependencyResponse{Node: dependency.NodeInfo, Constraint: dependency.Edge.Constraint, Kind: dependency.Edge.Kind.String()}
+		}
+		return result, nil
+	}
+	return view.TransitiveDependencies(key)
+}
+
+// dependencyResponse is a direct dependency, with the constraint and the kind of the edge that leads to it.
+type dependencyResponse struct {
+	Node       g.NodeInfo `json:"node"`
+	Constraint string     `json:"constraint"`
+	Kind       string     `json:"kind"`
+}
+
+func (s *Server) dependents(r *http.Request) (any, error) {
+	view, key, err := s.viewAndKey(r)
+	if err != nil {
+		return nil, err
+	}
+	return view.TransitiveDependents(key)
+}
+
+func (s *Server) resolve(r *http.Request) (any, error) {
+	view, key, err := s.viewAndKey(r)
+	if err != nil {
+		return nil, err
+	}
+	if asOf := r.URL.Query().Get("as-of"); asOf != "" {
+		t, err := parseDate("as-of", asOf)
+		if err != nil {
+			return nil, err
+		}
+		return view.ResolveAsOf(key, t)
+	}
+	return view.LatestTransitiveDependencies(key)
+}
+
+func (s *Server) between(r *http.Request) (any, error) {
+	query := r.URL.Query()
+	if query.Get("from") == "" || query.Get("to") == "" {
+		return nil, fmt.Errorf("%w: from and to must be given", errBadRequest)
+	}
+	view, _, err := s.view(r)
+	if err != nil {
+		return nil, err
+	}
+	return view.Nodes(), nil
+}
+
+// scoreResponse is the score of a node, given by PageRank or betweenness.
+type scoreResponse struct {
+	Node  g.NodeInfo `json:"node"`
+	Score float64    `json:"score"`
+}
+
+// packageScoreResponse is the sum of the scores of all the versions of a package.
+type packageScoreResponse struct {
+	Ecosystem string  `json:"ecosystem"`
+	Name      string  `json:"name"`
+	Score     float64 `json:"score"`
+}
+
+type rankResponse struct {
+	Nodes    []scoreResponse        `json:"nodes"`
+	Packages []packageScoreResponse `json:"packages"`
+}
+
+func (s *Server) rank(r *http.Request) (any, error) {
+	top, err := parseTop(r)
+	if err != nil {
+		return nil, err
+	}
+	view, filterKey, err := s.view(r)
+	if err != nil {
+		return nil, err
+	}
+	ranks, err := s.centrality.get(r.Context(), "pagerank?"+filterKey, func() (map[int64]float64, error) {
+		return g.PageRank(s.ctx, view.Directed(), nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	type packageKey struct{ ecosystem, name string }
+	aggregated := make(map[packageKey]float64)
+	for id, rank := range ranks {
+		if info, ok := view.Node(id); ok {
+			aggregated[packageKey{info.Ecosystem, info.Name}] += rank
+		}
+	}
+	packages := make([]packageScoreResponse, 0, len(aggregated))
+	for pkg, rank := range aggregated {
+		packages = append(packages, packageScoreResponse{Ecosystem: pkg.ecosystem, Name: pkg.name, Score: rank})
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		a, b := packages[i], packages[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Ecosystem < b.Ecosystem || a.Ecosystem == b.Ecosystem && a.Name < b.Name
+	})
+	if len(packages) > top {
+		packages = packages[:top]
+	}
+	return rankResponse{Nodes: s.topNodes(view, ranks, top), Packages: packages}, nil
+}
+
+func (s *Server) betweenness(r *http.Request) (any, error) {
+	top, err := parseTop(r)
+	if err != nil {
+		return nil, err
+	}
+	betweenness, err := s.centrality.get(r.Context(), "betweenness", func() (map[int64]float64, error) {
+		return g.Betweenness(s.ctx, s.pg.Directed(), nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.topNodes(s.pg, betweenness, top), nil
+}
+
+// topNodes returns the top nodes with the highest scores. Nodes with the same score are sorted by id, so the result
+// does not depend on the iteration order of the scores.
+func (s *Server) topNodes(view *g.PackageGraph, scores map[int64]float64, top int) []scoreResponse {
+	ids := make([]int64, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	result := make([]scoreResponse, 0, top)
+	for _, id := range ids {
+		if len(result) == top {
+			break
+		}
+		if info, ok := view.Node(id); ok {
+			result = append(result, scoreResponse{Node: info, Score: scores[id]})
+		}
+	}
+	return result
+}
+
+// view returns the part of the graph selected by the from, to and kind query parameters, and the key of the filters.
+// See filter.
+func (s *Server) view(r *http.Request) (*g.PackageGraph, string, error) {
+	query := r.URL.Query()
+	return s.filter(query.Get("from"), query.Get("to"), query["kind"])
+}
+
+// filter returns the part of the graph released between the dates from and to, if they are given, in which only the
+// dependencies of the given kinds are followed, if any are given. It also returns a key made of the parsed filters,
+// which is the same for all the parameters that select the same view.
+func (s *Server) filter(from, to string, kindNames []string) (*g.PackageGraph, string, error) {
+	var filters []g.Filter
+	var beginTime, endTime time.Time
+	if from != "" || to != "" {
+		if from == "" || to == "" {
+			return nil, "", fmt.Errorf("%w: from and to must be used together", errBadRequest)
+		}
+		var err error
+		if beginTime, err = parseDate("from", from); err != nil {
+			return nil, "", err
+		}
+		if endTime, err = parseDate("to", to); err != nil {
+			return nil, "", err
+		}
+		filters = append(filters, g.ReleasedBetween(beginTime, endTime))
+	}
+	var kinds g.DependencyKind
+	for _, name := range kindNames {
+		kind, err := g.ParseDependencyKind(name)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: kind: %v", errBadRequest, err)
+		}
+		kinds |= kind
+	}
+	if kinds != 0 {
+		filters = append(filters, g.OfKinds(kinds))
+	}
+	view, err := s.pg.Filter(filters...)
+	if err != nil {
+		return nil, "", err
+	}
+	key := fmt.Sprintf("from=%d&to=%d&kind=%d", beginTime.Unix(), endTime.Unix(), kinds)
+	return view, key, nil
+}
+
+// viewAndKey returns the view selected by the query parameters and the key of the package given by the package,
+// version and ecosystem parameters. The ecosystem can be left out when only one ecosystem has that version.
+func (s *Server) viewAndKey(r *http.Request) (*g.PackageGraph, g.NodeKey, error) {
+	query := r.URL.Query()
+	key := g.NodeKey{Ecosystem: query.Get("ecosystem"), Name: query.Get("package"), Version: query.Get("version")}
+	if key.Name == "" || key.Version == "" {
+		return nil, key, fmt.Errorf("%w: package and version must be given", errBadRequest)
+	}
+	key, err := s.completeKey(key)
+	if err != nil {
+		return nil, key, err
+	}
+	view, _, err := s.view(r)
+	return view, key, err
+}
+
+// completeKey fills in the ecosystem of the key when it is missing, which is only possible when a single ecosystem
+// has the version of the package.
+func (s *Server) completeKey(key g.NodeKey) (g.NodeKey, error) {
+	if key.Ecosystem != "" {
+		return key, nil
+	}
+	var found []string
+	for _, ecosystem := range s.pg.Ecosystems() {
+		if _, ok := s.pg.LookupKey(g.NodeKey{Ecosystem: ecosystem, Name: key.Name, Version: key.Version}); ok {
+			found = append(found, ecosystem)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return key, fmt.Errorf("%w: %s@%s", g.ErrPackageNotFound, key.Name, key.Version)
+	case 1:
+		key.Ecosystem = found[0]
+		return key, nil
+	default:
+		return key, fmt.Errorf("%w: ecosystem must be given, %s@%s is in %s", errBadRequest, key.Name, key.Version,
+			strings.Join(found, ", "))
+	}
+}
+
+func parseDate(name, value string) (time.Time, error) {
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s must be in the format DD-MM-YYYY", errBadRequest, name)
+	}
+	return t, nil
+}
+
+func parseTop(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("top")
+	if value == "" {
+		return defaultTop, nil
+	}
+	top, err := strconv.Atoi(value)
+	if err != nil || top <= 0 {
+		return 0, fmt.Errorf("%w: top must be a number larger than 0", errBadRequest)
+	}
+	return top, nil
+}
+
+// centralityCache holds the scores computed for the most recent requests, so they are not computed again for every
+// request. It also limits the number of scores computed at the same time.
+type centralityCache struct {
+	mu      sync.Mutex
+	results map[string]*centralityResult
+	// recent holds the keys of the results, from the least to the most recently used
+	recent []string
+	// running holds a token for every computation in progress
+	running chan struct{}
+}
+
+type centralityResult struct {
+	done   chan struct{}
+	scores map[int64]float64
+	err    error
+}
+
+// get returns the scores cached under key, and computes them with compute when they are not cached. Failed
+// computations are not cached. It returns an error wrapping errBusy when the scores are not cached and too many
+// computations are in progress, and the error of ctx when ctx is cancelled before the scores are known.
+func (c *centralityCache) get(ctx context.Context, key string, compute func() (map[int64]float64, error)) (map[int64]float64, error) {
+	c.mu.Lock()
+	result, ok := c.results[key]
+	if !ok {
+		select {
+		case c.running <- struct{}{}:
+		default:
+			c.mu.Unlock()
+			return nil, fmt.Errorf("%w, try again later", errBusy)
+		}
+		result = &centralityResult{done: make(chan struct{})}
+		c.results[key] = result
+		c.evict()
+		go func() {
+			result.scores, result.err = compute()
+			<-c.running
+			if result.err != nil {
+				c.mu.Lock()
+				if c.results[key] == result {
+					c.remove(key)
+				}
+				c.mu.Unlock()
+			}
+			close(result.done)
+		}()
+	}
+	c.touch(key)
+	c.mu.Unlock()
+
+	select {
+	case <-result.done:
+		return result.scores, result.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// touch marks the result cached under key as the most recently used. c.mu must be held.
+func (c *centralityCache) touch(key string) {
+	for i, k := range c.recent {
+		if k == key {
+			c.recent = append(c.recent[:i], c.recent[i+1:]...)
+			break
+		}
+	}
+	c.recent = append(c.recent, key)
+}
+
+// remove removes the result cached under key. c.mu must be held.
+func (c *centralityCache) remove(key string) {
+	delete(c.results, key)
+	for i, k := range c.recent {
+		if k == key {
+			c.recent = append(c.recent[:i], c.recent[i+1:]...)
+			break
+		}
+	}
+}
+
+// evict removes the least recently used results that are done until at most maxCentralityResults are cached. The
+// results in progress are kept, which is always possible since there are fewer of them than maxCentralityResults.
+// c.mu must be held.
+func (c *centralityCache) evict() {
+	for i := 0; len(c.results) > maxCentralityResults && i < len(c.recent); {
+		key := c.recent[i]
+		select {
+		case <-c.results[key].done:
+			c.remove(key)
+		default:
+			i++
+		}
+	}
+}
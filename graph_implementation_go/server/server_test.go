@@ -0,0 +1,215 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	g "github.com/AJMBrands/SoftwareThatMatters/graph"
+)
+
+func createTestServer(t *testing.T) *Server {
+	packagesInfo := []g.PackageInfo{
+		{Name: "A", Versions: map[string]g.VersionInfo{
+			"1.0.0": {Timestamp: "2021-04-01T20:15:37Z", Dependencies: map[string]string{}},
+			"2.0.0": {Timestamp: "2021-06-01T20:15:37Z", Dependencies: map[string]string{}},
+		}},
+		{Name: "B", Versions: map[string]g.VersionInfo{
+			"1.0.0": {Timestamp: "2021-04-22T20:15:37Z", Dependencies: map[string]string{"A": ">=1.0.0"}},
+		}},
+		{Name: "C", Versions: map[string]g.VersionInfo{
+			"1.0.0": {Timestamp: "2022-04-22T20:13:34Z", Dependencies: map[string]string{"B": "1.0.0"}},
+		}},
+	}
+	directed := g.NewDirectedGraph()
+	index, nodeMap := g.CreateMaps(&packagesInfo, directed)
+	if _, err := g.CreateEdges(context.Background(), directed, &packagesInfo, index, nodeMap, g.SemverDialect); err != nil {
+		t.Fatal(err)
+	}
+	return New(context.Background(), g.NewPackageGraph(directed, index, nodeMap))
+}
+
+// get sends a GET request to the server and decodes the response into result.
+func get(t *testing.T, s *Server, target string, result any) int {
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+	if err := json.NewDecoder(recorder.Body).Decode(result); err != nil {
+		t.Fatalf("Decoding the response to %s failed: %v", target, err)
+	}
+	return recorder.Code
+}
+
+// keys returns the name@version of every node.
+func keys(nodes []g.NodeInfo) []string {
+	result := make([]string, len(nodes))
+	for i, node := range nodes {
+		result[i] = node.Key().String()
+	}
+	return result
+}
+
+func TestServerQueries(t *testing.T) {
+	s := createTestServer(t)
+
+	for _, test := range []struct {
+		target   string
+		expected []string
+	}{
+		{"/dependencies?package=C&version=1.0.0", []string{"C@1.0.0", "A@1.0.0", "A@2.0.0", "B@1.0.0"}},
+		{"/dependencies?package=B&version=1.0.0&from=01-01-2021&to=01-05-2021", []string{"B@1.0.0", "A@1.0.0"}},
+		{"/resolve?package=C&version=1.0.0", []string{"C@1.0.0", "A@2.0.0", "B@1.0.0"}},
+		{"/resolve?package=C&version=1.0.0&as-of=01-05-2022", []string{"C@1.0.0", "A@2.0.0", "B@1.0.0"}},
+		{"/dependents?package=A&version=2.0.0", []string{"A@2.0.0", "B@1.0.0", "C@1.0.0"}},
+		{"/between?from=01-01-2021&to=31-12-2021", []string{"A@1.0.0", "A@2.0.0", "B@1.0.0"}},
+	} {
+		var nodes []g.NodeInfo
+		if status := get(t, s, test.target, &nodes); status != http.StatusOK {
+			t.Errorf("Expected %s to succeed, got %d", test.target, status)
+			continue
+		}
+		// Only the first node of a traversal has a fixed position, and /between has none
+		actual, first := keys(nodes), 1
+		if strings.HasPrefix(test.target, "/between") {
+			first = 0
+		}
+		actual = append(actual[:first], sortedCopy(actual[first:])...)
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("Expected %v for %s, got %v", test.expected, test.target, actual)
+		}
+	}
+
+	var rank rankResponse
+	if status := get(t, s, "/rank?top=1", &rank); status != http.StatusOK || len(rank.Nodes) != 1 || len(rank.Packages) != 1 ||
+		rank.Packages[0].Name != "A" {
+		t.Errorf("Expected A to be ranked first, got %d %v", status, rank)
+	}
+	var betweenness []scoreResponse
+	if status := get(t, s, "/betweenness", &betweenness); status != http.StatusOK || len(betweenness) != 1 ||
+		betweenness[0].Node.Key().String() != "B@1.0.0" {
+		t.Errorf("Expected only B to have a betweenness, got %d %v", status, betweenness)
+	}
+}
+
+func sortedCopy(values []string) []string {
+	result := append([]string(nil), values...)
+	sort.Strings(result)
+	return result
+}
+
+func TestServerErrors(t *testing.T) {
+	s := createTestServer(t)
+
+	for target, expected := range map[string]int{
+		"/dependencies?package=D&version=1.0.0":            http.StatusNotFound,
+		"/dependencies?package=C":                          http.StatusBadRequest,
+		"/dependencies?package=C&version=1.0.0&from=today": http.StatusBadRequest,
+		"/resolve?package=C&version=1.0.0&kind=build":      http.StatusBadRequest,
+		"/between?from=01-01-2021":                         http.StatusBadRequest,
+		"/rank?top=0":                                      http.StatusBadRequest,
+	} {
+		var response errorResponse
+		if status := get(t, s, target, &response); status != expected || response.Error == "" {
+			t.Errorf("Expected %d with an error for %s, got %d %v", expected, target, status, response)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/rank", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected POST to be refused, got %d", recorder.Code)
+	}
+}
+
+func TestServerConcurrentRequests(t *testing.T) {
+	s := createTestServer(t)
+
+	// Run with -race: the requests share the graph, including the reverse index used by /dependents
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		target := "/dependents?package=A&version=2.0.0"
+		if i%2 == 1 {
+			target = "/dependencies?package=C&version=1.0.0"
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			recorder := httptest.NewRecorder()
+			s.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+			if recorder.Code != http.StatusOK {
+				t.Errorf("Expected %s to succeed, got %d", target, recorder.Code)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestCentralityCache(t *testing.T) {
+	s := createTestServer(t)
+	ctx := context.Background()
+	computations := 0
+	compute := func() (map[int64]float64, error) {
+		computations++
+		return map[int64]float64{}, nil
+	}
+
+	t.Run("Equivalent filters share their scores", func(t *testing.T) {
+		var keys []string
+		for _, kinds := range [][]string{{"runtime", "dev"}, {"dev", "runtime", "dev"}} {
+			_, key, err := s.filter("01-01-2021", "31-12-2021", kinds)
+			if err != nil {
+				t.Fatal(err)
+			}
+			keys = append(keys, key)
+			if _, err := s.centrality.get(ctx, key, compute); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if keys[0] != keys[1] || computations != 1 {
+			t.Errorf("Expected the same key and a single computation, got %v and %d computations", keys, computations)
+		}
+	})
+
+	t.Run("Only keeps the most recent scores", func(t *testing.T) {
+		for i := 0; i < 2*maxCentralityResults; i++ {
+			if _, err := s.centrality.get(ctx, strconv.Itoa(i), compute); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(s.centrality.results) != maxCentralityResults || len(s.centrality.recent) != maxCentralityResults {
+			t.Errorf("Expected %d cached scores, got %d", maxCentralityResults, len(s.centrality.results))
+		}
+		if _, ok := s.centrality.results[strconv.Itoa(2*maxCentralityResults-1)]; !ok {
+			t.Error("Expected the most recent scores to be cached")
+		}
+	})
+
+	t.Run("Limits the concurrent computations", func(t *testing.T) {
+		release := make(chan struct{})
+		blocked := func() (map[int64]float64, error) {
+			<-release
+			return map[int64]float64{}, nil
+		}
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		for i := 0; i < maxCentralityComputations; i++ {
+			if _, err := s.centrality.get(cancelled, "blocked"+strconv.Itoa(i), blocked); !errors.Is(err, context.Canceled) {
+				t.Fatalf("Expected the computation to start, got %v", err)
+			}
+		}
+		if _, err := s.centrality.get(ctx, "one too many", compute); !errors.Is(err, errBusy) {
+			t.Errorf("Expected errBusy, got %v", err)
+		}
+		close(release)
+		if _, err := s.centrality.get(ctx, "blocked0", blocked); err != nil {
+			t.Fatal(err)
+		}
+	})
+}